@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
+	"vegeta-server/internal/cluster"
 	"vegeta-server/internal/dispatcher"
 	"vegeta-server/internal/endpoints"
+	"vegeta-server/internal/notifier"
 	"vegeta-server/internal/reporter"
 	"vegeta-server/models"
 	"vegeta-server/pkg/vegeta"
@@ -15,6 +18,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	log "github.com/sirupsen/logrus"
 
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
@@ -27,11 +32,27 @@ var (
 )
 
 var (
-	ip        = kingpin.Flag("ip", "Server IP Address.").Default("0.0.0.0").String()
-	port      = kingpin.Flag("port", "Server Port.").Default("80").String()
-	redisHost = kingpin.Flag("redis", "Redis Server Address.").String()
-	v         = kingpin.Flag("version", "Version Info").Short('v').Bool()
-	debug     = kingpin.Flag("debug", "Enabled Debug").Bool()
+	ip            = kingpin.Flag("ip", "Server IP Address.").Default("0.0.0.0").String()
+	port          = kingpin.Flag("port", "Server Port.").Default("80").String()
+	store         = kingpin.Flag("store", "Attack store, memory by default, or redis if --redis is set.").Enum("memory", "redis", "file")
+	dataDir       = kingpin.Flag("data-dir", "Directory of the database file of the file store.").Default("data").String()
+	spoolDir      = kingpin.Flag("spool-dir", "Directory the results of attacks are spooled to, and read from by reports.").Default(os.TempDir()).String()
+	redisHost     = kingpin.Flag("redis", "Redis Server Address.").String()
+	redisPrefix   = kingpin.Flag("redis-prefix", "Prefix of all the keys stored in Redis.").Default("vegeta-server:").String()
+	redisCompress = kingpin.Flag("redis-compress-results", "Compress the attack results stored in Redis with gzip.").Bool()
+	v             = kingpin.Flag("version", "Version Info").Short('v').Bool()
+	debug         = kingpin.Flag("debug", "Enabled Debug").Bool()
+	webhooks      = kingpin.Flag("webhook", "Webhook URL notified of the status transitions of all attacks. Repeatable.").Strings()
+	webhookSecret = kingpin.Flag("webhook-secret", "Secret used to sign the webhook payloads.").String()
+	maxConcurrent = kingpin.Flag("max-concurrent", "Maximum number of attacks running at once, 0 for no limit.").Default("0").Int()
+	fairQueue     = kingpin.Flag("fair-queue", "Share the attack queue fairly between users.").Bool()
+	coordinator   = kingpin.Flag("coordinator", "Split the attacks across the registered workers rather than running them locally.").Bool()
+	join          = kingpin.Flag("join", "URL of the coordinator to register with as a worker.").String()
+	advertiseURL  = kingpin.Flag("advertise-url", "URL the coordinator reaches this worker at, required with --join.").String()
+
+	retentionMaxAge   = kingpin.Flag("retention-max-age", "Time ended attacks are kept for, 0 to keep them forever.").Default("0").Duration()
+	retentionMaxCount = kingpin.Flag("retention-max-count", "Maximum number of attacks kept, 0 for no limit.").Default("0").Int()
+	retentionMaxBytes = kingpin.Flag("retention-max-bytes", "Maximum size of the attack results kept, e.g. 512MB, 0 for no limit.").Default("0").Bytes()
 )
 
 func main() {
@@ -56,33 +77,102 @@ func main() {
 		gin.DisableConsoleColor()
 	}
 
+	if err := os.MkdirAll(*spoolDir, 0700); err != nil {
+		log.WithError(err).Fatalf("Failed to create spool directory %s", *spoolDir)
+	}
+	vegeta.SpoolDir = *spoolDir
+
 	quit := make(chan struct{})
 	defer close(quit)
 
 	var db models.IAttackStore
 
-	if redisHost != nil && *redisHost != "" {
-		db = models.NewRedis(func() redis.Conn {
+	switch {
+	case *store == "file":
+		if err := os.MkdirAll(*dataDir, 0700); err != nil {
+			log.WithError(err).Fatalf("Failed to create data directory %s", *dataDir)
+		}
+		fileStore, err := models.NewBolt(filepath.Join(*dataDir, "vegeta-server.db"))
+		if err != nil {
+			log.WithError(err).Fatal("Failed to open file store")
+		}
+
+		canceled, err := models.CancelInterrupted(fileStore, "server restarted")
+		if err != nil {
+			log.WithError(err).Warn("Failed to cancel the attacks interrupted by the last shutdown")
+		}
+		if len(canceled) > 0 {
+			log.WithField("Canceled", len(canceled)).Info("canceled the attacks interrupted by the last shutdown")
+		}
+		db = fileStore
+	case *store == "redis" || (*store == "" && redisHost != nil && *redisHost != ""):
+		if redisHost == nil || *redisHost == "" {
+			log.Fatal("Set the redis-server address with --redis")
+		}
+
+		// Ended attacks are evicted by the retention sweeper rather than
+		// expired by Redis, so that their spool files are removed too
+		redisStore := models.NewRedis(func() redis.Conn {
 			conn, err := redis.Dial("tcp", *redisHost)
 			if err != nil {
 				log.Fatalf("Failed to connect to redis-server @ %s", *redisHost)
 			}
 			return conn
-		})
-	} else {
+		}, *redisPrefix, 0, *redisCompress)
+
+		// Rebuild the indexes of the stored attacks, in case they were lost
+		if err := redisStore.Reindex(); err != nil {
+			log.WithError(err).Warn("Failed to reindex the attacks stored in redis")
+		}
+		db = redisStore
+	default:
 		db = models.NewTaskMap()
 	}
 
+	// Coordinators run the attacks on their workers. The cluster has its own
+	// quit chan, as the signal handler stops the dispatcher only.
+	clusterQuit := make(chan struct{})
+	defer close(clusterQuit)
+
+	attack := dispatcher.AttackFunc(vegeta.Attack)
+	var c cluster.ICluster
+	if *coordinator {
+		coordinatorCluster := cluster.NewCluster()
+		go coordinatorCluster.Run(clusterQuit)
+		attack = coordinatorCluster.Attack
+		c = coordinatorCluster
+	}
+
+	if *join != "" {
+		if *advertiseURL == "" {
+			log.Fatal("Set the URL the coordinator reaches this worker at with --advertise-url")
+		}
+		go cluster.Join(*join, *advertiseURL, clusterQuit)
+	}
+
 	d := dispatcher.NewDispatcher(
 		db,
-		vegeta.Attack,
+		attack,
+		notifier.NewNotifier(*webhooks, *webhookSecret),
+		dispatcher.QueueOptions{
+			MaxConcurrent: *maxConcurrent,
+			Fairness:      *fairQueue,
+		},
+		dispatcher.RetentionPolicy{
+			MaxAge:   *retentionMaxAge,
+			MaxCount: *retentionMaxCount,
+			MaxBytes: int64(*retentionMaxBytes),
+		},
 	)
 
+	// Export the dispatcher metrics on /metrics
+	prometheus.MustRegister(d)
+
 	r := reporter.NewReporter(db)
 
 	go d.Run(quit)
 
-	engine := endpoints.SetupRouter(d, r)
+	engine := endpoints.SetupRouter(d, r, c)
 
 	sig := make(chan os.Signal, 1)
 
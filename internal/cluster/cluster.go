@@ -0,0 +1,598 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"vegeta-server/models"
+	"vegeta-server/pkg/vegeta"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// HealthInterval is the interval between the health checks of the workers
+	HealthInterval = 5 * time.Second
+	// WorkerTTL is the time after which workers that neither registered nor
+	// passed a health check are removed
+	WorkerTTL = time.Minute
+	// PollInterval is the interval at which the status of the attacks run by
+	// the workers is polled
+	PollInterval = time.Second
+	// maxPollFailures is the number of consecutive failures at polling the
+	// status of an attack after which the worker is given up on
+	maxPollFailures = 3
+	// downloadIdleTimeout is the time after which the download of a result
+	// that stopped receiving data is given up on
+	downloadIdleTimeout = 30 * time.Second
+)
+
+// WorkerLabel is set on the attacks run by the workers, to the ID of the
+// coordinator attack they are a share of
+const WorkerLabel = "coordinator-attack"
+
+// ICluster provides an interface for the worker registry of a coordinator
+type ICluster interface {
+	// Register a worker by its URL, or refresh the registration of a known
+	// worker
+	Register(string) models.Worker
+	// Deregister a worker by its ID
+	Deregister(string) error
+	// Workers returns the registered workers
+	Workers() []models.Worker
+}
+
+type cluster struct {
+	mu      *sync.Mutex
+	workers map[string]*models.Worker
+	client  *http.Client
+	// downloader downloads results, which may take longer than the timeout
+	// of the client, and are bounded by downloadIdleTimeout instead
+	downloader *http.Client
+}
+
+// NewCluster returns an instance of the cluster object, without workers
+func NewCluster() *cluster { // nolint: golint
+	return &cluster{
+		&sync.Mutex{},
+		make(map[string]*models.Worker),
+		&http.Client{Timeout: 30 * time.Second},
+		&http.Client{},
+	}
+}
+
+// Register a worker, which is healthy until a health check fails
+func (c *cluster) Register(url string) models.Worker {
+	url = strings.TrimRight(url, "/")
+	now := time.Now().Format(time.RFC1123)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, w := range c.workers {
+		if w.URL == url {
+			w.Healthy = true
+			w.Failures = 0
+			w.LastSeen = now
+			return *w
+		}
+	}
+
+	w := &models.Worker{
+		ID:           uuid.NewV4().String(),
+		URL:          url,
+		Healthy:      true,
+		RegisteredAt: now,
+		LastSeen:     now,
+	}
+	c.workers[w.ID] = w
+
+	c.log(w.ID).WithField("URL", url).Info("worker registered")
+
+	return *w
+}
+
+// Deregister a worker. Attacks already running on the worker carry on.
+func (c *cluster) Deregister(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.workers[id]; !ok {
+		return fmt.Errorf("worker with id %s not found", id)
+	}
+	delete(c.workers, id)
+
+	return nil
+}
+
+// Workers returns the registered workers, sorted by URL
+func (c *cluster) Workers() []models.Worker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	workers := make([]models.Worker, 0, len(c.workers))
+	for _, w := range c.workers {
+		workers = append(workers, *w)
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].URL < workers[j].URL })
+
+	return workers
+}
+
+// Run the health checks of the workers every HealthInterval, until quit
+func (c *cluster) Run(quit chan struct{}) {
+	ticker := time.NewTicker(HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			c.checkHealth(now)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// checkHealth checks the health of all workers, and removes the workers
+// unseen for WorkerTTL
+func (c *cluster) checkHealth(now time.Time) {
+	for _, w := range c.Workers() {
+		err := c.ping(w)
+
+		c.mu.Lock()
+		worker, ok := c.workers[w.ID]
+		if !ok {
+			c.mu.Unlock()
+			continue
+		}
+
+		if err == nil {
+			worker.Healthy = true
+			worker.Failures = 0
+			worker.LastSeen = now.Format(time.RFC1123)
+			c.mu.Unlock()
+			continue
+		}
+
+		worker.Healthy = false
+		worker.Failures++
+		lastSeen, _ := time.Parse(time.RFC1123, worker.LastSeen)
+		expired := now.Sub(lastSeen) > WorkerTTL
+		if expired {
+			delete(c.workers, w.ID)
+		}
+		c.mu.Unlock()
+
+		c.log(w.ID).WithError(err).Warn("worker health check failed")
+		if expired {
+			c.log(w.ID).Warn("worker removed")
+		}
+	}
+}
+
+// ping requests the health endpoint of a worker
+func (c *cluster) ping(w models.Worker) error {
+	resp, err := c.client.Get(w.URL + "/healthz")
+	if err != nil {
+		return err
+	}
+	return drain(resp)
+}
+
+// markFailed marks a worker unhealthy, until it passes a health check
+func (c *cluster) markFailed(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if w, ok := c.workers[id]; ok {
+		w.Healthy = false
+		w.Failures++
+	}
+}
+
+// healthy returns the healthy workers
+func (c *cluster) healthy() []models.Worker {
+	healthy := make([]models.Worker, 0)
+	for _, w := range c.Workers() {
+		if w.Healthy {
+			healthy = append(healthy, w)
+		}
+	}
+	return healthy
+}
+
+type workerResult struct {
+	worker models.Worker
+	spool  *vegeta.Spool
+	err    error
+}
+
+// Attack implements the dispatcher AttackFunc type for a coordinator. The
+// attack rate is split across the healthy workers, and the results of the
+// workers are merged once they all ended. Workers failing the attack are
+// marked unhealthy, and the results of the other workers are kept, so that
+// the attack only fails if all workers failed it. Rolling metrics are not
+// streamed.
+func (c *cluster) Attack(name string, params models.AttackParams, quit chan struct{}, progress chan<- models.AttackMetrics) (io.Reader, error) { // nolint: lll
+	workers := c.healthy()
+	if len(workers) == 0 {
+		return nil, errors.New("no healthy worker to run the attack")
+	}
+
+	shares := splitParams(name, params, len(workers))
+	results := make(chan workerResult, len(shares))
+	stop := make(chan struct{})
+	for i, share := range shares {
+		go func(w models.Worker, share models.AttackParams) {
+			spool, err := c.run(w, share, stop)
+			results <- workerResult{w, spool, err}
+		}(workers[i], share)
+	}
+
+	spools := make([]*vegeta.Spool, 0, len(shares))
+	failures := make([]string, 0)
+	canceled := false
+	for range shares {
+		select {
+		case r := <-results:
+			spools, failures = c.collect(r, spools, failures)
+			continue
+		case <-quit:
+		}
+
+		// The workers are canceled, and the attack ends once they all ended
+		if !canceled {
+			canceled = true
+			close(stop)
+		}
+		spools, failures = c.collect(<-results, spools, failures)
+	}
+
+	defer func() {
+		for _, s := range spools {
+			_ = s.Discard()
+		}
+	}()
+
+	if canceled {
+		return nil, nil
+	}
+
+	if len(spools) == 0 {
+		return nil, fmt.Errorf("all workers failed the attack: %s", strings.Join(failures, "; "))
+	}
+	if len(failures) > 0 {
+		log.WithField("ID", name).Warnf("merging the results of %d out of %d workers", len(spools), len(shares))
+	}
+
+	readers := make([]io.Reader, 0, len(spools))
+	for _, s := range spools {
+		readers = append(readers, s)
+	}
+	merged, err := vegeta.Merge(name, readers...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge the worker results")
+	}
+
+	return merged, nil
+}
+
+// collect the result of a worker
+func (c *cluster) collect(r workerResult, spools []*vegeta.Spool, failures []string) ([]*vegeta.Spool, []string) {
+	if r.err != nil {
+		if r.err != errCanceled {
+			c.markFailed(r.worker.ID)
+			c.log(r.worker.ID).WithError(r.err).Error("worker failed the attack")
+		}
+		return spools, append(failures, fmt.Sprintf("%s: %s", r.worker.URL, r.err))
+	}
+	return append(spools, r.spool), failures
+}
+
+// splitParams splits the attack params into the shares of at most n workers.
+// The rates are split as evenly as possible, with fewer workers than n when
+// there are not enough requests per second to go around.
+func splitParams(name string, params models.AttackParams, n int) []models.AttackParams {
+	// Each worker gets at least 1 request per second, a positive ramp step,
+	// and a sine amplitude both positive and lower than its rate
+	amp := rampAmplitude(params)
+	for _, rate := range []int{params.Rate, rampStep(params.Ramp), amp, params.Rate - amp} {
+		if rate > 0 && rate < n {
+			n = rate
+		}
+	}
+
+	rates := make([]int, n)
+	for i := range rates {
+		rates[i] = splitRate(params.Rate, n, i)
+	}
+	var amps []int
+	if amp > 0 {
+		amps = splitAmplitude(amp, params.Rate, rates)
+	}
+
+	shares := make([]models.AttackParams, n)
+	for i := range shares {
+		share := params
+		share.Rate = rates[i]
+		if params.Ramp != nil {
+			ramp := *params.Ramp
+			ramp.To = splitRate(ramp.To, n, i)
+			ramp.Step = splitRate(ramp.Step, n, i)
+			if amps != nil {
+				ramp.Amplitude = amps[i]
+			} else {
+				ramp.Amplitude = splitRate(ramp.Amplitude, n, i)
+			}
+			share.Ramp = &ramp
+		}
+
+		// The coordinator notifies the webhooks and evaluates the assertions
+		// of the whole attack
+		share.Webhooks = nil
+		share.Assertions = nil
+
+		share.Labels = map[string]string{WorkerLabel: name}
+		for k, v := range params.Labels {
+			share.Labels[k] = v
+		}
+
+		shares[i] = share
+	}
+
+	return shares
+}
+
+// splitRate returns the share i of a rate split between n workers
+func splitRate(rate, n, i int) int {
+	share := rate / n
+	if i < rate%n {
+		share++
+	}
+	return share
+}
+
+// splitAmplitude splits a sine ramp amplitude between the shares of the rate
+// in proportion to their rates, keeping each share of the amplitude positive
+// and lower than the share of the rate. The rate must be split between fewer
+// shares than both the amplitude and the rate left over it.
+func splitAmplitude(amp, rate int, rates []int) []int {
+	amps := make([]int, len(rates))
+	sum := 0
+	for i, r := range rates {
+		amps[i] = amp * r / rate
+		if amps[i] < 1 {
+			amps[i] = 1
+		}
+		if amps[i] > r-1 {
+			amps[i] = r - 1
+		}
+		sum += amps[i]
+	}
+
+	// Hand out what rounding left over, or take back what clamping added
+	for i := 0; sum != amp; i = (i + 1) % len(amps) {
+		if sum < amp && amps[i] < rates[i]-1 {
+			amps[i]++
+			sum++
+		} else if sum > amp && amps[i] > 1 {
+			amps[i]--
+			sum--
+		}
+	}
+	return amps
+}
+
+// rampAmplitude returns the amplitude of a valid sine ramp, zero otherwise
+func rampAmplitude(params models.AttackParams) int {
+	if params.Ramp == nil || params.Ramp.Type != models.RampTypeSine {
+		return 0
+	}
+	if amp := params.Ramp.Amplitude; amp > 0 && amp < params.Rate {
+		return amp
+	}
+	return 0
+}
+
+func rampStep(ramp *models.Ramp) int {
+	if ramp == nil || ramp.Type != models.RampTypeStep {
+		return 0
+	}
+	return ramp.Step
+}
+
+// errCanceled is returned for the attacks canceled on the workers
+var errCanceled = errors.New("attack canceled")
+
+// run a share of an attack on a worker, and download its result once
+// completed. The attack is canceled on the worker when stop is closed.
+func (c *cluster) run(w models.Worker, params models.AttackParams, stop chan struct{}) (*vegeta.Spool, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var attack models.AttackResponse
+	if err = c.do(http.MethodPost, w.URL+"/api/v1/attack", body, &attack); err != nil {
+		return nil, errors.Wrap(err, "failed to submit attack")
+	}
+
+	failures := 0
+	for {
+		select {
+		case <-stop:
+			cancel, _ := json.Marshal(models.AttackCancel{
+				Cancel: true,
+				By:     models.AttackCanceledByServer,
+				Reason: "coordinator attack canceled",
+			})
+			if err = c.do(http.MethodPost, w.URL+"/api/v1/attack/"+attack.ID+"/cancel", cancel, nil); err != nil {
+				c.log(w.ID).WithError(err).Warnf("failed to cancel attack %s", attack.ID)
+			}
+			return nil, errCanceled
+		case <-time.After(PollInterval):
+		}
+
+		if err = c.do(http.MethodGet, w.URL+"/api/v1/attack/"+attack.ID, nil, &attack); err != nil {
+			if failures++; failures >= maxPollFailures {
+				return nil, errors.Wrap(err, "failed to get attack status")
+			}
+			continue
+		}
+		failures = 0
+
+		switch attack.Status {
+		case models.AttackResponseStatusCompleted:
+			return c.download(w, attack.ID)
+		case models.AttackResponseStatusFailed:
+			if attack.Error != nil {
+				return nil, fmt.Errorf("attack %s failed: %s", attack.ID, attack.Error.Message)
+			}
+			return nil, fmt.Errorf("attack %s failed", attack.ID)
+		case models.AttackResponseStatusCanceled:
+			return nil, fmt.Errorf("attack %s canceled on the worker", attack.ID)
+		}
+	}
+}
+
+// download the result of a completed attack to a spool, and delete the attack
+// from the worker
+func (c *cluster) download(w models.Worker, id string) (*vegeta.Spool, error) {
+	req, err := http.NewRequest(http.MethodGet, w.URL+"/api/v1/report/"+id+"?format=binary", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download result")
+	}
+
+	// The download is canceled once no data is received for
+	// downloadIdleTimeout, however long it takes overall
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(downloadIdleTimeout, cancel)
+	defer idle.Stop()
+
+	resp, err := c.downloader.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download result")
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download result: unexpected status code %d", resp.StatusCode)
+	}
+
+	spool, err := vegeta.NewSpool(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(spool, &idleReader{resp.Body, idle}); err == nil {
+		err = spool.Close()
+	}
+	if err != nil {
+		_ = spool.Discard()
+		return nil, errors.Wrap(err, "failed to download result")
+	}
+
+	// The coordinator keeps the merged result
+	if err = c.do(http.MethodDelete, w.URL+"/api/v1/attack/"+id, nil, nil); err != nil {
+		c.log(w.ID).WithError(err).Warnf("failed to delete attack %s", id)
+	}
+
+	return spool, nil
+}
+
+// idleReader resets the idle timer of a download whenever data is read
+type idleReader struct {
+	r    io.Reader
+	idle *time.Timer
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.idle.Reset(downloadIdleTimeout)
+	}
+	return n, err
+}
+
+// do sends a request to a worker, and decodes the JSON response into v
+func (c *cluster) do(method, url string, body []byte, v interface{}) error {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	if v == nil || resp.StatusCode != http.StatusOK {
+		return drain(resp)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// drain reads and closes a response body, and returns an error for
+// unexpected status codes
+func drain(resp *http.Response) error {
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(b))
+	}
+	return nil
+}
+
+func (c *cluster) log(id string) *log.Entry {
+	return log.WithFields(log.Fields{
+		"component": "cluster",
+		"Worker":    id,
+	})
+}
+
+// Join registers a worker with a coordinator every HealthInterval, until quit.
+// Registering again keeps the worker known to the coordinator, should the
+// coordinator restart.
+func Join(coordinator, url string, quit chan struct{}) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	body, _ := json.Marshal(models.WorkerRegistration{URL: url})
+	l := log.WithFields(log.Fields{
+		"component":   "cluster",
+		"Coordinator": coordinator,
+	})
+
+	ticker := time.NewTicker(HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := client.Post(strings.TrimRight(coordinator, "/")+"/api/v1/workers", "application/json", bytes.NewReader(body))
+		if err == nil {
+			err = drain(resp)
+		}
+		if err != nil {
+			l.WithError(err).Warn("failed to register with the coordinator")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,457 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"vegeta-server/models"
+	"vegeta-server/pkg/vegeta"
+
+	lib "github.com/tsenart/vegeta/lib"
+)
+
+// fakeWorker implements the worker endpoints used by the coordinator. Its
+// attacks end with the status, and complete with the results.
+type fakeWorker struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   models.AttackStatus
+	results  int
+	healthy  bool
+	params   []models.AttackParams
+	canceled []string
+}
+
+func newFakeWorker(status models.AttackStatus, results int) *fakeWorker {
+	w := &fakeWorker{status: status, results: results, healthy: true}
+	w.Server = httptest.NewServer(http.HandlerFunc(w.serve))
+	return w
+}
+
+func (w *fakeWorker) serve(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	path := r.URL.Path
+	switch {
+	case path == "/healthz":
+		if !w.healthy {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+	case r.Method == http.MethodPost && path == "/api/v1/attack":
+		var params models.AttackParams
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		w.params = append(w.params, params)
+		_ = json.NewEncoder(rw).Encode(models.AttackResponse{ID: "worker-attack", Status: models.AttackResponseStatusRunning})
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/cancel"):
+		w.canceled = append(w.canceled, path)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/attack/"):
+		resp := models.AttackResponse{ID: "worker-attack", Status: w.status}
+		if w.status == models.AttackResponseStatusFailed {
+			resp.Error = &models.AttackError{Message: "oops"}
+		}
+		_ = json.NewEncoder(rw).Encode(resp)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/report/"):
+		enc := lib.NewEncoder(rw)
+		for i := 0; i < w.results; i++ {
+			_ = enc.Encode(&lib.Result{Attack: "worker-attack", Seq: uint64(i), Code: 200, Timestamp: time.Now()})
+		}
+	case r.Method == http.MethodDelete:
+	default:
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// shortPollInterval shortens PollInterval, until the returned func is called
+func shortPollInterval() func() {
+	old := PollInterval
+	PollInterval = 10 * time.Millisecond
+	return func() { PollInterval = old }
+}
+
+func Test_splitParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params models.AttackParams
+		n      int
+		want   []int
+	}{
+		{"Even", models.AttackParams{Rate: 100}, 4, []int{25, 25, 25, 25}},
+		{"Remainder", models.AttackParams{Rate: 10}, 3, []int{4, 3, 3}},
+		{"Fewer requests than workers", models.AttackParams{Rate: 2}, 3, []int{1, 1}},
+		{
+			"Step ramp",
+			models.AttackParams{Rate: 100, Ramp: &models.Ramp{Type: models.RampTypeStep, Step: 2, Every: "1s"}},
+			3,
+			[]int{50, 50},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int, 0)
+			for _, share := range splitParams("123", tt.params, tt.n) {
+				got = append(got, share.Rate)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitParams() rates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_splitParams_Ramp(t *testing.T) {
+	sine := func(rate, amp int) models.AttackParams {
+		ramp := &models.Ramp{Type: models.RampTypeSine, Amplitude: amp, Period: "1m"}
+		return models.AttackParams{Rate: rate, Duration: "10s", Ramp: ramp}
+	}
+
+	tests := []struct {
+		name   string
+		params models.AttackParams
+		n      int
+		want   int
+	}{
+		{"Sine", sine(100, 30), 4, 4},
+		{"Sine with a large amplitude", sine(10, 9), 4, 1},
+		{"Sine with a small amplitude", sine(10, 2), 3, 2},
+		{"Sine with uneven rates", sine(10, 4), 4, 4},
+		{
+			"Linear",
+			models.AttackParams{Rate: 10, Duration: "10s", Ramp: &models.Ramp{Type: models.RampTypeLinear, To: 2}},
+			3,
+			3,
+		},
+		{
+			"Step",
+			models.AttackParams{
+				Rate:     10,
+				Duration: "10s",
+				Ramp:     &models.Ramp{Type: models.RampTypeStep, To: 11, Step: 3, Every: "1s"},
+			},
+			4,
+			3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := splitParams("123", tt.params, tt.n)
+			if len(shares) != tt.want {
+				t.Errorf("splitParams() shares = %d, want %d", len(shares), tt.want)
+			}
+
+			// Each share is accepted by its worker, and the shares add up to
+			// the attack
+			rate, amp := 0, 0
+			for _, share := range shares {
+				if err := vegeta.ValidateRamp(share); err != nil {
+					t.Errorf("splitParams() share %+v error = %v", *share.Ramp, err)
+				}
+				rate += share.Rate
+				amp += share.Ramp.Amplitude
+			}
+			if rate != tt.params.Rate || amp != tt.params.Ramp.Amplitude {
+				t.Errorf("splitParams() shares rate = %d, amplitude = %d, want %d, %d",
+					rate, amp, tt.params.Rate, tt.params.Ramp.Amplitude)
+			}
+		})
+	}
+}
+
+func Test_splitParams_Fields(t *testing.T) {
+	params := models.AttackParams{
+		Rate:       10,
+		Ramp:       &models.Ramp{Type: models.RampTypeLinear, To: 20},
+		Assertions: []string{"p99 < 100ms"},
+		Webhooks:   []string{"http://hooks"},
+		Labels:     map[string]string{"team": "payments"},
+	}
+
+	shares := splitParams("123", params, 2)
+	for _, share := range shares {
+		if share.Ramp.To != 10 || share.Assertions != nil || share.Webhooks != nil {
+			t.Errorf("splitParams() share = %+v", share)
+		}
+		if want := map[string]string{"team": "payments", WorkerLabel: "123"}; !reflect.DeepEqual(share.Labels, want) {
+			t.Errorf("splitParams() labels = %v, want %v", share.Labels, want)
+		}
+	}
+
+	// The params are left untouched
+	if params.Ramp.To != 20 || len(params.Labels) != 1 {
+		t.Errorf("splitParams() changed the params: %+v", params)
+	}
+}
+
+func TestCluster_Register(t *testing.T) {
+	c := NewCluster()
+
+	w := c.Register("http://worker-1:80/")
+	if w.URL != "http://worker-1:80" || !w.Healthy {
+		t.Errorf("Register() = %+v", w)
+	}
+	if again := c.Register("http://worker-1:80"); again.ID != w.ID {
+		t.Errorf("Register() again = %+v, want worker %s", again, w.ID)
+	}
+	c.Register("http://worker-0:80")
+
+	got := c.Workers()
+	if len(got) != 2 || got[0].URL != "http://worker-0:80" {
+		t.Errorf("Workers() = %+v", got)
+	}
+
+	if err := c.Deregister(w.ID); err != nil {
+		t.Fatalf("Deregister() error = %v", err)
+	}
+	if err := c.Deregister(w.ID); err == nil {
+		t.Error("Deregister() of a missing worker succeeded")
+	}
+	if got = c.Workers(); len(got) != 1 {
+		t.Errorf("Workers() = %+v, want 1 worker", got)
+	}
+}
+
+func TestCluster_checkHealth(t *testing.T) {
+	healthy := newFakeWorker(models.AttackResponseStatusCompleted, 0)
+	defer healthy.Close()
+	unhealthy := newFakeWorker(models.AttackResponseStatusCompleted, 0)
+	defer unhealthy.Close()
+	unhealthy.healthy = false
+
+	c := NewCluster()
+	c.Register(healthy.URL)
+	c.Register(unhealthy.URL)
+
+	c.checkHealth(time.Now())
+	for _, w := range c.Workers() {
+		if want := w.URL == healthy.URL; w.Healthy != want {
+			t.Errorf("worker %s healthy = %v, want %v", w.URL, w.Healthy, want)
+		}
+	}
+	if got := c.healthy(); len(got) != 1 {
+		t.Errorf("healthy() = %+v, want 1 worker", got)
+	}
+
+	// Unhealthy workers unseen for WorkerTTL are removed
+	c.checkHealth(time.Now().Add(2 * WorkerTTL))
+	got := c.Workers()
+	if len(got) != 1 || got[0].URL != healthy.URL {
+		t.Errorf("Workers() = %+v, want the healthy worker only", got)
+	}
+}
+
+func TestCluster_Attack(t *testing.T) {
+	defer shortPollInterval()()
+
+	tests := []struct {
+		name        string
+		statuses    []models.AttackStatus
+		wantResults int
+		wantErr     bool
+	}{
+		{
+			name:        "All completed",
+			statuses:    []models.AttackStatus{models.AttackResponseStatusCompleted, models.AttackResponseStatusCompleted},
+			wantResults: 4,
+		},
+		{
+			name:        "Partial failure",
+			statuses:    []models.AttackStatus{models.AttackResponseStatusCompleted, models.AttackResponseStatusFailed},
+			wantResults: 2,
+		},
+		{
+			name:     "All failed",
+			statuses: []models.AttackStatus{models.AttackResponseStatusFailed, models.AttackResponseStatusCanceled},
+			wantErr:  true,
+		},
+		{
+			name:    "No workers",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCluster()
+			for _, status := range tt.statuses {
+				w := newFakeWorker(status, 2)
+				defer w.Close()
+				c.Register(w.URL)
+			}
+
+			r, err := c.Attack("123", models.AttackParams{Rate: 10}, make(chan struct{}), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Attack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			spool := r.(*vegeta.Spool)
+			defer spool.Discard() // nolint: errcheck
+
+			m, err := vegeta.NewAttackMetricsFromReader(spool, "123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int(m.Requests) != tt.wantResults {
+				t.Errorf("merged requests = %d, want %d", m.Requests, tt.wantResults)
+			}
+
+			// Workers failing the attack are unhealthy
+			if got := len(c.healthy()); got != tt.wantResults/2 {
+				t.Errorf("healthy workers = %d, want %d", got, tt.wantResults/2)
+			}
+		})
+	}
+}
+
+func TestCluster_Attack_Merge(t *testing.T) {
+	defer shortPollInterval()()
+
+	workers := []*fakeWorker{
+		newFakeWorker(models.AttackResponseStatusCompleted, 3),
+		newFakeWorker(models.AttackResponseStatusCompleted, 0),
+	}
+	c := NewCluster()
+	for _, w := range workers {
+		defer w.Close()
+		c.Register(w.URL)
+	}
+
+	r, err := c.Attack("123", models.AttackParams{Rate: 5}, make(chan struct{}), nil)
+	if err != nil {
+		t.Fatalf("Attack() error = %v", err)
+	}
+	spool := r.(*vegeta.Spool)
+	defer spool.Discard() // nolint: errcheck
+
+	// The results of the workers are merged as the results of the attack,
+	// and workers without results are skipped
+	dec := lib.NewDecoder(spool)
+	for i := 0; i < 3; i++ {
+		var res lib.Result
+		if err := dec.Decode(&res); err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if res.Attack != "123" {
+			t.Errorf("result attack = %s, want 123", res.Attack)
+		}
+	}
+
+	// The rate is split between the workers
+	rates := make([]int, 0)
+	for _, w := range workers {
+		for _, p := range w.params {
+			rates = append(rates, p.Rate)
+		}
+	}
+	if len(rates) != 2 || rates[0]+rates[1] != 5 {
+		t.Errorf("worker rates = %v, want a total of 5", rates)
+	}
+}
+
+func TestCluster_Attack_Cancel(t *testing.T) {
+	defer shortPollInterval()()
+
+	w := newFakeWorker(models.AttackResponseStatusRunning, 0)
+	defer w.Close()
+
+	c := NewCluster()
+	c.Register(w.URL)
+
+	quit := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		r, err := c.Attack("123", models.AttackParams{Rate: 10}, quit, nil)
+		if r != nil {
+			err = fmt.Errorf("canceled attack returned a result")
+		}
+		done <- err
+	}()
+
+	quit <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatalf("Attack() error = %v", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.canceled) != 1 {
+		t.Errorf("worker cancellations = %v, want 1", w.canceled)
+	}
+}
+
+func TestCluster_download(t *testing.T) {
+	defer func(timeout time.Duration) { downloadIdleTimeout = timeout }(downloadIdleTimeout)
+	downloadIdleTimeout = 100 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		wait    time.Duration
+		wantErr bool
+	}{
+		// Downloads last as long as data is received
+		{"Slow", 20 * time.Millisecond, false},
+		{"Stalled", 300 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete {
+					return
+				}
+
+				enc := lib.NewEncoder(rw)
+				for i := 0; i < 10; i++ {
+					_ = enc.Encode(&lib.Result{Seq: uint64(i), Code: 200, Timestamp: time.Now()})
+					rw.(http.Flusher).Flush()
+					time.Sleep(tt.wait)
+				}
+			}))
+			defer srv.Close()
+
+			c := NewCluster()
+			spool, err := c.download(models.Worker{URL: srv.URL}, "123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("download() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer spool.Discard() // nolint: errcheck
+
+			m, err := vegeta.NewAttackMetricsFromReader(spool, "123")
+			if err != nil || m.Requests != 10 {
+				t.Errorf("downloaded requests = %d, %v, want 10", m.Requests, err)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	registered := make(chan models.WorkerRegistration, 1)
+	coordinator := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var registration models.WorkerRegistration
+		b, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &registration)
+		if r.URL.Path == "/api/v1/workers" {
+			registered <- registration
+		}
+	}))
+	defer coordinator.Close()
+
+	quit := make(chan struct{})
+	go Join(coordinator.URL+"/", "http://worker-1:80", quit)
+
+	if got := <-registered; got.URL != "http://worker-1:80" {
+		t.Errorf("registration = %+v", got)
+	}
+	close(quit)
+}
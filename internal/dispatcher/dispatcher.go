@@ -2,11 +2,16 @@ package dispatcher
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"time"
 	"vegeta-server/pkg/vegeta"
 
+	uuid "github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 
 	"sync"
+	"vegeta-server/internal/notifier"
 	"vegeta-server/models"
 
 	"github.com/pkg/errors"
@@ -17,6 +22,14 @@ var (
 	defaultAttackFn = vegeta.Attack
 )
 
+// ErrActive is the cause of the errors of deleting scheduled or running
+// attacks without canceling them
+var ErrActive = errors.New("attack is scheduled or running")
+
+// ErrEnded is the cause of the errors of streaming the metrics of attacks
+// that have ended
+var ErrEnded = errors.New("attack has ended")
+
 // IDispatcher provides an interface for attack dispatch operations.
 type IDispatcher interface {
 	// Run the dispatcher event loop
@@ -24,12 +37,39 @@ type IDispatcher interface {
 	// Dispatch an attack. Used by the client/handler
 	Dispatch(models.AttackParams) (*models.AttackResponse, error)
 	// Cancel a scheduled/on-going attack
-	Cancel(string, bool) error
+	Cancel(string, models.AttackCancel) error
+	// Delete an attack along with its report. Scheduled and running attacks
+	// are refused, unless set to be canceled first.
+	Delete(string, bool) error
+	// DeleteAll deletes the attacks matching the filters. Scheduled and
+	// running attacks are skipped, unless set to be canceled first.
+	DeleteAll(models.FilterParams, bool) models.AttackDeleteResponse
 
 	// Get the attack status, params and ID for a single attack
 	Get(string) (*models.AttackResponse, error)
-	// List the attack status, params and ID for all submitted attacks.
-	List(models.FilterParams) []*models.AttackResponse
+	// List the attack status, params and ID for the submitted attacks matching
+	// the filters, one page at a time.
+	List(models.FilterParams, models.ListParams) (models.AttackList, error)
+	// Stream the rolling metrics of a scheduled/on-going attack. The returned
+	// func must be called to stop streaming.
+	Stream(string) (<-chan models.AttackMetrics, func(), error)
+	// Deliveries returns the webhook delivery log of an attack
+	Deliveries(string) ([]models.WebhookDelivery, error)
+	// Queue returns the attacks waiting to run, in the order they are expected to run
+	Queue() models.QueueResponse
+	// Retention returns the retention policy of ended attacks, and the attacks it evicted
+	Retention() models.RetentionResponse
+
+	// Schedule an attack to run at a later time, or on a recurring basis
+	Schedule(models.ScheduleParams) (*models.ScheduleResponse, error)
+	// GetSchedule returns the schedule status, params and ID for a single schedule
+	GetSchedule(string) (*models.ScheduleResponse, error)
+	// ListSchedules returns the schedule status, params and ID for all schedules
+	ListSchedules() []*models.ScheduleResponse
+	// PauseSchedule pauses or resumes a schedule
+	PauseSchedule(string, bool) error
+	// DeleteSchedule deletes a schedule. Attacks it created are left as is.
+	DeleteSchedule(string) error
 }
 
 type dispatcher struct {
@@ -39,10 +79,17 @@ type dispatcher struct {
 	submitCh chan ITask
 	updateCh chan UpdateMessage
 	db       models.IAttackStore
+
+	schedules map[string]*schedule
+	notifier  notifier.INotifier
+	queue     *queue
+	retention retention
 }
 
-// NewDispatcher constructs a new instance of the dispatcher object.
-func NewDispatcher(db models.IAttackStore, fn AttackFunc) *dispatcher { // nolint: golint
+// NewDispatcher constructs a new instance of the dispatcher object. Attacks
+// wait in a queue, configured by opts, until they can run. Ended attacks are
+// evicted according to the retention policy.
+func NewDispatcher(db models.IAttackStore, fn AttackFunc, n notifier.INotifier, opts QueueOptions, policy RetentionPolicy) *dispatcher { // nolint: golint, lll
 	if db == nil {
 		db = defaultDB
 	}
@@ -51,6 +98,11 @@ func NewDispatcher(db models.IAttackStore, fn AttackFunc) *dispatcher { // nolin
 		fn = defaultAttackFn
 	}
 
+	// Attack webhooks are notified even without server webhooks
+	if n == nil {
+		n = notifier.NewNotifier(nil, "")
+	}
+
 	d := &dispatcher{
 		&sync.RWMutex{},
 		make(map[string]ITask),
@@ -58,6 +110,11 @@ func NewDispatcher(db models.IAttackStore, fn AttackFunc) *dispatcher { // nolin
 		make(chan ITask, 10),
 		make(chan UpdateMessage, 20),
 		db,
+
+		make(map[string]*schedule),
+		n,
+		newQueue(opts),
+		retention{policy, 0, 0, nil, false},
 	}
 	d.log(nil).Info("creating new dispatcher")
 	return d
@@ -84,7 +141,7 @@ func (d *dispatcher) Dispatch(params models.AttackParams) (*models.AttackRespons
 	d.log(fields).Info("dispatching new attack")
 	d.submitCh <- task
 
-	attackDetails, err := d.db.GetByID(id)
+	attackDetails, err := d.db.GetInfoByID(id)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get attack by ID")
 	}
@@ -98,6 +155,18 @@ func (d *dispatcher) Dispatch(params models.AttackParams) (*models.AttackRespons
 func (d *dispatcher) Run(quit chan struct{}) {
 	defer close(d.submitCh)
 	d.log(nil).Info("starting dispatcher")
+
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+
+	// Ended attacks are only swept with a retention policy
+	var sweepTick <-chan time.Time
+	if d.retention.policy.enabled() {
+		sweepTicker := time.NewTicker(retentionInterval)
+		defer sweepTicker.Stop()
+		sweepTick = sweepTicker.C
+	}
+
 	for {
 		select {
 		case task := <-d.submitCh:
@@ -108,40 +177,114 @@ func (d *dispatcher) Run(quit chan struct{}) {
 
 			d.log(fields).Debug("received task")
 
-			if err := task.Run(d.attackFn); err != nil {
-				d.log(fields).WithError(err).Errorf("failed to run %s", task.ID())
-				continue
-			}
+			d.mu.Lock()
+			d.queue.push(task)
+			d.mu.Unlock()
+
+			d.runQueued()
 		case update := <-d.updateCh:
 			fields := log.Fields{
 				"ID":     update.ID,
 				"Status": update.Status,
 			}
 
+			// The lock is held while updating the store, so that deleted
+			// attacks are not stored again
 			d.mu.RLock()
-			task := d.tasks[update.ID]
-			d.mu.RUnlock()
+			task, ok := d.tasks[update.ID]
+			if !ok {
+				d.mu.RUnlock()
+				d.log(fields).Debug("received update for deleted attack")
+				continue
+			}
 
-			if err := d.db.Update(task.ID(), attackDetailFromTask(task)); err != nil {
+			details := attackDetailFromTask(task)
+			err := d.db.Update(task.ID(), details)
+			d.mu.RUnlock()
+			if err != nil {
 				d.log(fields).WithError(err).Error("attack update error")
 				continue
 			}
 			d.log(fields).Debug("received update for attack")
-		case <-quit:
-			for _, task := range d.tasks {
-				_ = task.Cancel()
+
+			d.notify(update.Status, details)
+
+			// Ended attacks make room for queued attacks
+			if !active(update.Status) {
+				d.mu.Lock()
+				d.queue.remove(update.ID)
+				d.mu.Unlock()
+
+				d.runQueued()
 			}
+		case now := <-ticker.C:
+			d.runDueSchedules(now)
+		case now := <-sweepTick:
+			d.startSweep(now)
+		case <-quit:
+			d.shutdown()
 			d.log(nil).Warning("gracefully shutting down the dispatcher")
 			return
 		}
 	}
 }
 
-// Cancel an attack by ID.
-func (d *dispatcher) Cancel(id string, cancel bool) error {
+// shutdown cancels the scheduled and running attacks, and stores their
+// canceled status. The results of ended attacks are kept, as the store may
+// outlive the server.
+func (d *dispatcher) shutdown() {
+	d.mu.RLock()
+	tasks := make([]ITask, 0, len(d.tasks))
+	for _, task := range d.tasks {
+		tasks = append(tasks, task)
+	}
+	d.mu.RUnlock()
+
+	// Canceling sends updates, which are received here as the event loop
+	// no longer runs
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, task := range tasks {
+			if active(task.Status()) {
+				_ = task.Cancel(models.AttackCanceledByServer, "server shutting down")
+			}
+		}
+	}()
+
+	store := func(update UpdateMessage) {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+
+		if task, ok := d.tasks[update.ID]; ok {
+			_ = d.db.Update(task.ID(), attackDetailFromTask(task))
+		}
+	}
+
+	for {
+		select {
+		case update := <-d.updateCh:
+			store(update)
+		case <-done:
+			// Updates sent before the last cancel are still buffered
+			for {
+				select {
+				case update := <-d.updateCh:
+					store(update)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Cancel an attack by ID. The attack is canceled by the user, unless the
+// params name who canceled it.
+func (d *dispatcher) Cancel(id string, params models.AttackCancel) error {
 	fields := log.Fields{
 		"ID":       id,
-		"ToCancel": cancel,
+		"ToCancel": params.Cancel,
 	}
 
 	d.log(fields).Info("canceling attack")
@@ -155,8 +298,13 @@ func (d *dispatcher) Cancel(id string, cancel bool) error {
 	}
 	d.mu.RUnlock()
 
-	if cancel {
-		err := t.Cancel()
+	if params.Cancel {
+		by := params.By
+		if by == "" {
+			by = models.AttackCanceledByUser
+		}
+
+		err := t.Cancel(by, params.Reason)
 		if err != nil {
 			d.log(fields).WithError(err).Error("failed to cancel task")
 			return errors.Wrap(err, "failed to cancel task")
@@ -166,6 +314,81 @@ func (d *dispatcher) Cancel(id string, cancel bool) error {
 	return nil
 }
 
+// Delete an attack by ID, along with its report and webhook delivery log.
+// Scheduled and running attacks are canceled first if cancel is set, and
+// refused otherwise.
+func (d *dispatcher) Delete(id string, cancel bool) error {
+	fields := log.Fields{
+		"ID":       id,
+		"ToCancel": cancel,
+	}
+
+	d.log(fields).Info("deleting attack")
+
+	d.mu.RLock()
+	t, ok := d.tasks[id]
+	d.mu.RUnlock()
+
+	if ok && active(t.Status()) {
+		if !cancel {
+			return errors.Wrap(ErrActive, fmt.Sprintf("cannot delete attack %s with status %s", id, t.Status()))
+		}
+
+		// The attack may end meanwhile, in which case there is nothing to cancel
+		_ = t.Cancel(models.AttackCanceledByUser, "attack deleted")
+	}
+
+	d.mu.Lock()
+	delete(d.tasks, id)
+	d.queue.remove(id)
+	d.mu.Unlock()
+
+	if ok {
+		if err := t.Discard(); err != nil {
+			d.log(fields).WithError(err).Warn("failed to remove result spool file")
+		}
+	} else if details, err := d.db.GetInfoByID(id); err == nil && details.ResultPath != "" {
+		// Attacks stored before a restart have no task, only their spool file
+		if err := os.Remove(details.ResultPath); err != nil && !os.IsNotExist(err) {
+			d.log(fields).WithError(err).Warn("failed to remove result spool file")
+		}
+	}
+
+	if err := d.db.Delete(id); err != nil {
+		d.log(fields).WithError(err).Error("failed to delete attack")
+		return errors.Wrap(err, "failed to delete attack")
+	}
+
+	d.notifier.Forget(id)
+
+	// A deleted running attack makes room for queued attacks
+	d.runQueued()
+
+	return nil
+}
+
+// DeleteAll deletes the attacks matching the filters. Scheduled and running
+// attacks are canceled first if cancel is set, and skipped otherwise.
+func (d *dispatcher) DeleteAll(filters models.FilterParams, cancel bool) models.AttackDeleteResponse {
+	resp := models.AttackDeleteResponse{
+		Deleted: make([]string, 0),
+		Skipped: make([]string, 0),
+	}
+
+	for _, attackDetails := range d.db.GetAll(filters) {
+		if err := d.Delete(attackDetails.ID, cancel); err != nil {
+			resp.Skipped = append(resp.Skipped, attackDetails.ID)
+			continue
+		}
+		resp.Deleted = append(resp.Deleted, attackDetails.ID)
+	}
+
+	sort.Strings(resp.Deleted)
+	sort.Strings(resp.Skipped)
+
+	return resp
+}
+
 // Get an attack by ID
 func (d *dispatcher) Get(id string) (*models.AttackResponse, error) {
 	fields := log.Fields{
@@ -174,27 +397,298 @@ func (d *dispatcher) Get(id string) (*models.AttackResponse, error) {
 
 	d.log(fields).Debug("getting attack status")
 
-	attackDetails, err := d.db.GetByID(id)
+	attackDetails, err := d.db.GetInfoByID(id)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get item by ID")
 	}
 	resp := models.AttackResponse(attackDetails.AttackInfo)
+	resp.QueuePosition = d.queuePositions()[id]
 	return &resp, nil
 }
 
-// List all submitted attacks
-func (d *dispatcher) List(filters models.FilterParams) []*models.AttackResponse {
+// List a page of the submitted attacks matching the filters
+func (d *dispatcher) List(filters models.FilterParams, params models.ListParams) (models.AttackList, error) {
 	d.log(nil).Debug("getting attack list")
 
-	responses := make([]*models.AttackResponse, 0)
+	attacks := d.db.GetAll(filters)
+	page, next, err := models.Paginate(attacks, params)
+	if err != nil {
+		return models.AttackList{}, errors.Wrap(err, "failed to paginate attacks")
+	}
 
-	for _, attackDetails := range d.db.GetAll(filters) {
+	responses := make([]*models.AttackResponse, 0, len(page))
+	positions := d.queuePositions()
+
+	for _, attackDetails := range page {
 		resp := models.AttackResponse(attackDetails.AttackInfo)
+		resp.QueuePosition = positions[resp.ID]
+		responses = append(responses, &resp)
+	}
+	return models.AttackList{
+		Attacks:    responses,
+		Total:      len(attacks),
+		NextCursor: next,
+	}, nil
+}
+
+// Queue returns the state of the attack queue
+func (d *dispatcher) Queue() models.QueueResponse {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.queue.response()
+}
+
+// queuePositions maps the ID of each queued attack to its position
+func (d *dispatcher) queuePositions() map[string]int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	positions := make(map[string]int)
+	for i, t := range d.queue.order() {
+		positions[t.ID()] = i + 1
+	}
+	return positions
+}
+
+// runQueued starts the queued attacks, up to the concurrency limit. Attacks
+// are started off the caller, as starting an attack sends an update to the
+// event loop, which may be the caller.
+func (d *dispatcher) runQueued() {
+	d.mu.Lock()
+	tasks := d.queue.next()
+	d.mu.Unlock()
+
+	for _, task := range tasks {
+		go d.start(task)
+	}
+}
+
+// start runs a queued attack
+func (d *dispatcher) start(task ITask) {
+	fields := log.Fields{
+		"ID":     task.ID(),
+		"Status": task.Status(),
+	}
+
+	// Attacks canceled while queued fail to run, and free their slot
+	if err := task.Run(d.attackFn); err != nil {
+		d.log(fields).WithError(err).Errorf("failed to run %s", task.ID())
+
+		d.mu.Lock()
+		d.queue.remove(task.ID())
+		d.mu.Unlock()
+
+		d.runQueued()
+	}
+}
+
+// Stream the rolling metrics of an attack by ID
+func (d *dispatcher) Stream(id string) (<-chan models.AttackMetrics, func(), error) {
+	fields := log.Fields{
+		"ID": id,
+	}
+
+	d.log(fields).Debug("streaming attack metrics")
+
+	d.mu.RLock()
+	t, ok := d.tasks[id]
+	d.mu.RUnlock()
+	if !ok {
+		// Attacks stored before a restart have no task, and have ended
+		if _, err := d.db.GetInfoByID(id); err == nil {
+			return nil, nil, errors.Wrap(ErrEnded, fmt.Sprintf("cannot stream attack %s", id))
+		}
+		return nil, nil, fmt.Errorf("cannot find task with id %s", id)
+	}
+
+	// Only ended tasks cannot be subscribed to
+	ch, stop, err := t.Subscribe()
+	if err != nil {
+		return nil, nil, errors.Wrap(ErrEnded, err.Error())
+	}
+
+	return ch, stop, nil
+}
+
+// runDueSchedules creates and runs a new attack for every schedule that is due
+func (d *dispatcher) runDueSchedules(now time.Time) {
+	tasks := make([]ITask, 0)
+
+	d.mu.Lock()
+	for _, s := range d.schedules {
+		if !s.due(now) {
+			continue
+		}
+
+		task := NewTask(d.updateCh, s.params.AttackParams)
+		task.scheduleID = s.id
+		d.tasks[task.ID()] = task
+
+		s.fired(task.ID(), now)
+		tasks = append(tasks, task)
+	}
+	d.mu.Unlock()
+
+	for _, task := range tasks {
+		fields := log.Fields{
+			"ID":         task.ID(),
+			"ScheduleID": task.ScheduleID(),
+		}
+
+		_ = d.db.Add(attackDetailFromTask(task))
+
+		d.log(fields).Info("dispatching scheduled attack")
+
+		d.mu.Lock()
+		d.queue.push(task)
+		d.mu.Unlock()
+	}
+
+	d.runQueued()
+}
+
+// Schedule an attack to run at a later time, or on a recurring basis
+func (d *dispatcher) Schedule(params models.ScheduleParams) (*models.ScheduleResponse, error) {
+	s, err := newSchedule(params, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create schedule")
+	}
+
+	d.mu.Lock()
+	d.schedules[s.id] = s
+	resp := s.response()
+	d.mu.Unlock()
+
+	d.log(log.Fields{"ScheduleID": s.id}).Info("scheduling new attack")
+
+	return &resp, nil
+}
+
+// GetSchedule returns a schedule by ID
+func (d *dispatcher) GetSchedule(id string) (*models.ScheduleResponse, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	s, ok := d.schedules[id]
+	if !ok {
+		return nil, fmt.Errorf("cannot find schedule with id %s", id)
+	}
+
+	resp := s.response()
+	return &resp, nil
+}
+
+// ListSchedules returns all schedules, oldest first
+func (d *dispatcher) ListSchedules() []*models.ScheduleResponse {
+	d.mu.RLock()
+	schedules := make([]*schedule, 0, len(d.schedules))
+	for _, s := range d.schedules {
+		schedules = append(schedules, s)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].createdAt.Before(schedules[j].createdAt)
+	})
+
+	responses := make([]*models.ScheduleResponse, 0, len(schedules))
+	for _, s := range schedules {
+		resp := s.response()
 		responses = append(responses, &resp)
 	}
+	d.mu.RUnlock()
+
 	return responses
 }
 
+// PauseSchedule pauses or resumes a schedule by ID
+func (d *dispatcher) PauseSchedule(id string, pause bool) error {
+	fields := log.Fields{
+		"ScheduleID": id,
+		"ToPause":    pause,
+	}
+
+	d.log(fields).Info("pausing schedule")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.schedules[id]
+	if !ok {
+		d.log(fields).Error("schedule not found")
+		return fmt.Errorf("cannot find schedule with id %s", id)
+	}
+
+	if err := s.pause(pause, time.Now()); err != nil {
+		d.log(fields).WithError(err).Error("failed to pause schedule")
+		return errors.Wrap(err, "failed to pause schedule")
+	}
+
+	return nil
+}
+
+// DeleteSchedule deletes a schedule by ID
+func (d *dispatcher) DeleteSchedule(id string) error {
+	d.log(log.Fields{"ScheduleID": id}).Info("deleting schedule")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.schedules[id]; !ok {
+		return fmt.Errorf("cannot find schedule with id %s", id)
+	}
+	delete(d.schedules, id)
+
+	return nil
+}
+
+// Deliveries returns the webhook delivery log of an attack by ID
+func (d *dispatcher) Deliveries(id string) ([]models.WebhookDelivery, error) {
+	if _, err := d.db.GetInfoByID(id); err != nil {
+		return nil, errors.Wrap(err, "failed to get item by ID")
+	}
+
+	return d.notifier.Deliveries(id), nil
+}
+
+// notify the webhooks of an attack status transition. Completed attacks
+// carry a summary of their metrics, which is computed off the event loop.
+func (d *dispatcher) notify(status models.AttackStatus, details models.AttackDetails) {
+	if status == models.AttackResponseStatusScheduled || !d.notifier.Enabled(details.Params.Webhooks) {
+		return
+	}
+
+	event := models.WebhookEvent{
+		ID:        uuid.NewV4().String(),
+		Status:    status,
+		Attack:    details.AttackInfo,
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+	}
+	event.Attack.Params = details.Params.Redacted()
+
+	if status != models.AttackResponseStatusCompleted {
+		d.notifier.Notify(event, details.Params.Webhooks)
+		return
+	}
+
+	// Completed is the last status of an attack, so its event is still
+	// notified after the previous events
+	go func() {
+		m, err := vegeta.NewAttackMetricsFromReader(vegeta.SpoolReader(details.ResultPath), details.ID)
+		if err != nil {
+			d.log(log.Fields{"ID": details.ID}).WithError(err).Warn("failed to summarise attack for webhooks")
+		} else {
+			event.Report = &m
+		}
+
+		d.notifier.Notify(event, details.Params.Webhooks)
+	}()
+}
+
+// active reports whether an attack with the status is yet to end
+func active(status models.AttackStatus) bool {
+	return status == models.AttackResponseStatusScheduled || status == models.AttackResponseStatusRunning
+}
+
 func (d *dispatcher) log(fields map[string]interface{}) *log.Entry {
 	l := log.WithField("component", "dispatcher")
 
@@ -1,17 +1,23 @@
 package dispatcher
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"reflect"
 	"strings"
 	"sync"
 	"testing"
 	"time"
+	"vegeta-server/internal/notifier"
+	nmocks "vegeta-server/internal/notifier/mocks"
 	"vegeta-server/models"
 	smocks "vegeta-server/models/mocks"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
+	vegeta "github.com/tsenart/vegeta/lib"
 )
 
 func TestNewDispatcher(t *testing.T) {
@@ -28,7 +34,7 @@ func TestNewDispatcher(t *testing.T) {
 			name: "OK",
 			args: args{
 				db: &smocks.IAttackStore{},
-				fn: func(s string, params models.AttackParams, i chan struct{}) (reader io.Reader, e error) {
+				fn: func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
 					return strings.NewReader("hello world"), nil
 				},
 			},
@@ -37,7 +43,7 @@ func TestNewDispatcher(t *testing.T) {
 		{
 			name: "OK - defaults db",
 			args: args{
-				fn: func(s string, params models.AttackParams, i chan struct{}) (reader io.Reader, e error) {
+				fn: func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
 					return strings.NewReader("hello world"), nil
 				},
 			},
@@ -53,7 +59,7 @@ func TestNewDispatcher(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewDispatcher(tt.args.db, tt.args.fn)
+			got := NewDispatcher(tt.args.db, tt.args.fn, nil, QueueOptions{}, RetentionPolicy{})
 			if tt.wantNil && got != nil {
 				t.Errorf("NewDispatcher() = %v, wantNit %v", got, tt.wantNil)
 			}
@@ -65,12 +71,15 @@ func setupDispatcher(db models.IAttackStore) *dispatcher {
 	d := &dispatcher{
 		mu:    new(sync.RWMutex),
 		tasks: make(map[string]ITask),
-		attackFn: func(s string, params models.AttackParams, i chan struct{}) (reader io.Reader, e error) {
+		attackFn: func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
 			return strings.NewReader("hello world"), nil
 		},
-		submitCh: make(chan ITask),
-		updateCh: make(chan UpdateMessage),
-		db:       db,
+		submitCh:  make(chan ITask),
+		updateCh:  make(chan UpdateMessage),
+		db:        db,
+		schedules: make(map[string]*schedule),
+		notifier:  notifier.NewNotifier(nil, ""),
+		queue:     newQueue(QueueOptions{}),
 	}
 
 	go func() {
@@ -104,7 +113,7 @@ func Test_dispatcher_Dispatch(t *testing.T) {
 				db := new(smocks.IAttackStore)
 
 				db.On("Add", mock.Anything).Return(nil)
-				db.On("GetByID", mock.Anything).Return(models.AttackDetails{}, nil)
+				db.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
 
 				return db
 			},
@@ -121,7 +130,7 @@ func Test_dispatcher_Dispatch(t *testing.T) {
 				db := new(smocks.IAttackStore)
 
 				db.On("Add", mock.Anything).Return(nil)
-				db.On("GetByID", mock.Anything).Return(models.AttackDetails{}, fmt.Errorf("error"))
+				db.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, fmt.Errorf("error"))
 
 				return db
 			},
@@ -154,7 +163,7 @@ func Test_dispatcher_Run(t *testing.T) {
 
 	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
 	mockStore.On("Add", mock.Anything).Return(nil)
-	mockStore.On("GetByID", mock.Anything).Return(models.AttackDetails{}, nil)
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
 
 	d := setupDispatcher(mockStore)
 
@@ -171,12 +180,217 @@ func Test_dispatcher_Run(t *testing.T) {
 	}
 }
 
-func Test_dispatcher_Run_Error_GetByID(t *testing.T) {
+func Test_dispatcher_Run_Webhooks(t *testing.T) {
 	mockStore := &smocks.IAttackStore{}
 
 	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
 	mockStore.On("Add", mock.Anything).Return(nil)
-	mockStore.On("GetByID", mock.Anything).Return(models.AttackDetails{}, fmt.Errorf("error"))
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
+
+	events := make(chan models.WebhookEvent, 10)
+	mockNotifier := &nmocks.INotifier{}
+	mockNotifier.On("Enabled", mock.Anything).Return(true)
+	mockNotifier.
+		On("Notify", mock.Anything, []string{"http://localhost/hook"}).
+		Run(func(args mock.Arguments) {
+			events <- args.Get(0).(models.WebhookEvent)
+		})
+
+	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
+		buf := new(bytes.Buffer)
+		err := vegeta.NewEncoder(buf).Encode(&vegeta.Result{Code: 200, Timestamp: time.Now()})
+		return buf, err
+	}, mockNotifier, QueueOptions{}, RetentionPolicy{})
+
+	quit := make(chan struct{})
+	defer func() {
+		quit <- struct{}{}
+	}()
+
+	go d.Run(quit)
+
+	resp, err := d.Dispatch(models.AttackParams{
+		Key:      "key",
+		Cert:     "cert",
+		Body:     "body",
+		Target:   models.Target{URL: "http://localhost/target", Body: "body"},
+		Webhooks: []string{"http://localhost/hook"},
+	})
+	if err != nil || resp == nil {
+		t.Fatal(err)
+	}
+
+	var got []models.AttackStatus
+	for len(got) < 2 {
+		select {
+		case event := <-events:
+			if _, ok := d.tasks[event.Attack.ID]; event.ID == "" || !ok {
+				t.Errorf("unexpected event %v", event)
+			}
+			// Secrets and bodies are not sent to the webhooks
+			params := event.Attack.Params
+			if params.Key != "" || params.Cert != "" || params.Body != "" || params.Target.Body != "" {
+				t.Errorf("event params = %+v, want no key, cert or bodies", params)
+			}
+			if params.Target.URL != "http://localhost/target" {
+				t.Errorf("event target URL = %s, want http://localhost/target", params.Target.URL)
+			}
+			if event.Status == models.AttackResponseStatusCompleted && (event.Report == nil || event.Report.Requests != 1) {
+				t.Errorf("completed event report = %v, want 1 request", event.Report)
+			}
+			got = append(got, event.Status)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for webhook events, got %v", got)
+		}
+	}
+
+	want := []models.AttackStatus{models.AttackResponseStatusRunning, models.AttackResponseStatusCompleted}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("webhook events = %v, want %v", got, want)
+	}
+}
+
+func Test_dispatcher_Run_Queue(t *testing.T) {
+	mockStore := &smocks.IAttackStore{}
+
+	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockStore.On("Add", mock.Anything).Return(nil)
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
+
+	release := make(chan struct{})
+	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
+		<-release
+		return nil, fmt.Errorf("done")
+	}, nil, QueueOptions{MaxConcurrent: 1}, RetentionPolicy{})
+
+	quit := make(chan struct{})
+	defer func() {
+		quit <- struct{}{}
+	}()
+
+	go d.Run(quit)
+
+	for i := 0; i < 2; i++ {
+		if _, err := d.Dispatch(models.AttackParams{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitForQueue := func(running, queued int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			q := d.Queue()
+			if q.Running == running && len(q.Queued) == queued {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("queue = %v, want %d running and %d queued", q, running, queued)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitForQueue(1, 1)
+	if q := d.Queue(); q.MaxConcurrent != 1 || q.Queued[0].Position != 1 {
+		t.Errorf("queue = %v", q)
+	}
+
+	// The queued attack runs once the running attack ends
+	release <- struct{}{}
+	waitForQueue(1, 0)
+
+	release <- struct{}{}
+	waitForQueue(0, 0)
+}
+
+func Test_dispatcher_runQueued_Unlimited(t *testing.T) {
+	mockStore := &smocks.IAttackStore{}
+
+	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	release := make(chan struct{})
+	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
+		<-release
+		return nil, fmt.Errorf("done")
+	}, nil, QueueOptions{}, RetentionPolicy{})
+
+	for i := 0; i < 2*cap(d.updateCh); i++ {
+		d.queue.push(NewTask(d.updateCh, models.AttackParams{}))
+	}
+
+	// Starting more attacks than the update channel buffers must not block
+	// the event loop, which is the only reader of the update channel
+	done := make(chan struct{})
+	go func() {
+		d.runQueued()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out starting queued attacks")
+	}
+
+	quit := make(chan struct{})
+	go d.Run(quit)
+	close(release)
+	quit <- struct{}{}
+}
+
+func Test_dispatcher_Run_Shutdown(t *testing.T) {
+	db := models.NewTaskMap()
+	d := NewDispatcher(db, func(s string, params models.AttackParams, quit chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
+		<-quit
+		return nil, nil
+	}, nil, QueueOptions{}, RetentionPolicy{})
+
+	completed := NewTask(make(chan UpdateMessage, 10), models.AttackParams{})
+	completed.status = models.AttackResponseStatusRunning
+	if err := completed.Complete(strings.NewReader("result")); err != nil {
+		t.Fatal(err)
+	}
+	defer completed.Discard() // nolint: errcheck
+	d.tasks[completed.ID()] = completed
+
+	quit := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		d.Run(quit)
+		close(stopped)
+	}()
+
+	resp, err := d.Dispatch(models.AttackParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for d.Queue().Running != 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	quit <- struct{}{}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out shutting down")
+	}
+
+	// Running attacks are canceled, while the results of ended attacks are
+	// kept for the store
+	if got, err := db.GetByID(resp.ID); err != nil || got.Status != models.AttackResponseStatusCanceled {
+		t.Errorf("running attack = %v, %v, want canceled", got.AttackInfo, err)
+	}
+	if _, err := os.Stat(completed.ResultPath()); err != nil {
+		t.Errorf("completed attack spool file error = %v", err)
+	}
+}
+
+func Test_dispatcher_Run_Error_GetInfoByID(t *testing.T) {
+	mockStore := &smocks.IAttackStore{}
+
+	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockStore.On("Add", mock.Anything).Return(nil)
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, fmt.Errorf("error"))
 
 	d := setupDispatcher(mockStore)
 
@@ -198,12 +412,12 @@ func Test_dispatcher_Cancel(t *testing.T) {
 
 	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
 	mockStore.On("Add", mock.Anything).Return(nil)
-	mockStore.On("GetByID", mock.Anything).Return(models.AttackDetails{}, nil)
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
 
-	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}) (reader io.Reader, e error) {
+	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
 		<-i
 		return nil, nil
-	})
+	}, nil, QueueOptions{}, RetentionPolicy{})
 
 	quit := make(chan struct{})
 	defer func() {
@@ -222,7 +436,7 @@ func Test_dispatcher_Cancel(t *testing.T) {
 
 	for _, task := range d.tasks {
 		id := task.ID()
-		err = d.Cancel(id, true)
+		err = d.Cancel(id, models.AttackCancel{Cancel: true})
 		if err != nil {
 			t.Fail()
 		}
@@ -234,11 +448,11 @@ func Test_dispatcher_Cancel_Error_completed(t *testing.T) {
 
 	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
 	mockStore.On("Add", mock.Anything).Return(nil)
-	mockStore.On("GetByID", mock.Anything).Return(models.AttackDetails{}, nil)
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
 
-	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}) (reader io.Reader, e error) {
+	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
 		return strings.NewReader("hello world"), nil
-	})
+	}, nil, QueueOptions{}, RetentionPolicy{})
 
 	quit := make(chan struct{})
 
@@ -254,7 +468,7 @@ func Test_dispatcher_Cancel_Error_completed(t *testing.T) {
 
 	for _, task := range d.tasks {
 		id := task.ID()
-		err := d.Cancel(id, true)
+		err := d.Cancel(id, models.AttackCancel{Cancel: true})
 		if err == nil {
 			t.Fail()
 		}
@@ -266,11 +480,11 @@ func Test_dispatcher_Cancel_Error_not_found(t *testing.T) {
 
 	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
 	mockStore.On("Add", mock.Anything).Return(nil)
-	mockStore.On("GetByID", mock.Anything).Return(models.AttackDetails{}, nil)
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
 
-	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}) (reader io.Reader, e error) {
+	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
 		return nil, nil
-	})
+	}, nil, QueueOptions{}, RetentionPolicy{})
 
 	quit := make(chan struct{})
 	defer func() {
@@ -279,7 +493,7 @@ func Test_dispatcher_Cancel_Error_not_found(t *testing.T) {
 
 	go d.Run(quit)
 
-	err := d.Cancel("123", true)
+	err := d.Cancel("123", models.AttackCancel{Cancel: true})
 	if err == nil {
 		t.Fail()
 	}
@@ -288,7 +502,7 @@ func Test_dispatcher_Cancel_Error_not_found(t *testing.T) {
 func Test_dispatcher_Get(t *testing.T) {
 	mockStore := &smocks.IAttackStore{}
 
-	mockStore.On("GetByID", mock.Anything).Return(models.AttackDetails{}, nil)
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
 
 	d := setupDispatcher(mockStore)
 
@@ -298,10 +512,10 @@ func Test_dispatcher_Get(t *testing.T) {
 	}
 }
 
-func Test_dispatcher_Get_Error_GetByID(t *testing.T) {
+func Test_dispatcher_Get_Error_GetInfoByID(t *testing.T) {
 	mockStore := &smocks.IAttackStore{}
 
-	mockStore.On("GetByID", mock.Anything).Return(models.AttackDetails{}, fmt.Errorf("error"))
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, fmt.Errorf("error"))
 
 	d := setupDispatcher(mockStore)
 
@@ -318,8 +532,8 @@ func Test_dispatcher_List(t *testing.T) {
 
 	d := setupDispatcher(mockStore)
 
-	got := d.List(make(models.FilterParams))
-	if len(got) == 0 {
+	got, err := d.List(make(models.FilterParams), models.ListParams{})
+	if err != nil || len(got.Attacks) == 0 || got.Total != 1 {
 		t.Fail()
 	}
 }
@@ -331,8 +545,333 @@ func Test_dispatcher_List_Empty(t *testing.T) {
 
 	d := setupDispatcher(mockStore)
 
-	got := d.List(make(models.FilterParams))
-	if len(got) != 0 {
+	got, err := d.List(make(models.FilterParams), models.ListParams{})
+	if err != nil || len(got.Attacks) != 0 || got.NextCursor != "" {
+		t.Fail()
+	}
+}
+
+func Test_dispatcher_Stream(t *testing.T) {
+	mockStore := &smocks.IAttackStore{}
+
+	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockStore.On("Add", mock.Anything).Return(nil)
+	mockStore.On("GetInfoByID", mock.Anything).Return(models.AttackDetails{}, nil)
+
+	subscribed := make(chan struct{})
+	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) { // nolint: lll
+		<-subscribed
+		p <- models.AttackMetrics{ID: s, Requests: 1}
+		return strings.NewReader("hello world"), nil
+	}, nil, QueueOptions{}, RetentionPolicy{})
+
+	quit := make(chan struct{})
+	defer func() {
+		quit <- struct{}{}
+	}()
+
+	go d.Run(quit)
+
+	_, err := d.Dispatch(models.AttackParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id string
+	d.mu.RLock()
+	for _, task := range d.tasks {
+		id = task.ID()
+	}
+	d.mu.RUnlock()
+
+	ch, stop, err := d.Stream(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	close(subscribed)
+
+	m, ok := <-ch
+	if !ok || m.ID != id || m.Requests != 1 {
+		t.Errorf("dispatcher.Stream() = %v, want metrics for %s", m, id)
+	}
+
+	// The stream is closed once the attack ends
+	if _, ok := <-ch; ok {
+		t.Error("dispatcher.Stream() channel not closed")
+	}
+}
+
+func Test_dispatcher_Stream_Error_not_found(t *testing.T) {
+	mockStore := &smocks.IAttackStore{}
+	mockStore.On("GetInfoByID", "123").Return(models.AttackDetails{}, fmt.Errorf("not found"))
+
+	d := setupDispatcher(mockStore)
+
+	_, _, err := d.Stream("123")
+	if err == nil || errors.Cause(err) == ErrEnded {
+		t.Errorf("dispatcher.Stream() error = %v, want not found", err)
+	}
+}
+
+func Test_dispatcher_Stream_Error_ended(t *testing.T) {
+	db := models.NewTaskMap()
+	d := setupDispatcher(db)
+
+	// Completed attacks, whether or not they have a task, cannot be streamed
+	completed := NewTask(make(chan UpdateMessage, 10), models.AttackParams{})
+	completed.status = models.AttackResponseStatusRunning
+	if err := completed.Complete(strings.NewReader("result")); err != nil {
+		t.Fatal(err)
+	}
+	defer completed.Discard() // nolint: errcheck
+	if err := db.Add(attackDetailFromTask(completed)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := d.Stream(completed.ID()); errors.Cause(err) != ErrEnded {
+		t.Errorf("dispatcher.Stream() error = %v, want %v for an attack without a task", err, ErrEnded)
+	}
+	d.tasks[completed.ID()] = completed
+	if _, _, err := d.Stream(completed.ID()); errors.Cause(err) != ErrEnded {
+		t.Errorf("dispatcher.Stream() error = %v, want %v for a completed task", err, ErrEnded)
+	}
+}
+
+func Test_dispatcher_Schedule(t *testing.T) {
+	mockStore := &smocks.IAttackStore{}
+
+	mockStore.On("Add", mock.Anything).Return(nil)
+
+	d := setupDispatcher(mockStore)
+
+	resp, err := d.Schedule(models.ScheduleParams{
+		StartAt: time.Now().Add(-time.Second).Format(time.RFC3339),
+	})
+	if err != nil || resp == nil || resp.Status != models.ScheduleStatusActive {
+		t.Fatalf("dispatcher.Schedule() = %v, %v", resp, err)
+	}
+
+	d.runDueSchedules(time.Now())
+
+	got, err := d.GetSchedule(resp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.ScheduleStatusDone || len(got.AttackIDs) != 1 {
+		t.Fatalf("dispatcher.GetSchedule() = %v, want done with one attack", got)
+	}
+
+	d.mu.RLock()
+	task, ok := d.tasks[got.AttackIDs[0]]
+	d.mu.RUnlock()
+	if !ok || task.ScheduleID() != resp.ID {
+		t.Errorf("scheduled attack not linked to schedule %s", resp.ID)
+	}
+}
+
+func Test_dispatcher_runDueSchedules_Many(t *testing.T) {
+	mockStore := &smocks.IAttackStore{}
+
+	mockStore.On("Add", mock.Anything).Return(nil)
+	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	release := make(chan struct{})
+	d := NewDispatcher(mockStore, func(s string, params models.AttackParams, i chan struct{}, p chan<- models.AttackMetrics) (reader io.Reader, e error) {
+		<-release
+		return nil, fmt.Errorf("done")
+	}, nil, QueueOptions{}, RetentionPolicy{})
+
+	for i := 0; i < 2*cap(d.updateCh); i++ {
+		_, err := d.Schedule(models.ScheduleParams{
+			StartAt: time.Now().Add(-time.Second).Format(time.RFC3339),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Running more due schedules than the update channel buffers must not
+	// block the event loop, which is the only reader of the update channel
+	done := make(chan struct{})
+	go func() {
+		d.runDueSchedules(time.Now())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out running due schedules")
+	}
+
+	quit := make(chan struct{})
+	go d.Run(quit)
+	close(release)
+	quit <- struct{}{}
+}
+
+func Test_dispatcher_Schedule_Error_invalid(t *testing.T) {
+	d := setupDispatcher(&smocks.IAttackStore{})
+
+	_, err := d.Schedule(models.ScheduleParams{Cron: "not a cron expression"})
+	if err == nil {
 		t.Fail()
 	}
 }
+
+func Test_dispatcher_ListSchedules(t *testing.T) {
+	d := setupDispatcher(&smocks.IAttackStore{})
+
+	for i := 0; i < 2; i++ {
+		if _, err := d.Schedule(models.ScheduleParams{Cron: "@hourly"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := d.ListSchedules(); len(got) != 2 {
+		t.Errorf("dispatcher.ListSchedules() = %v, want 2 schedules", got)
+	}
+}
+
+func Test_dispatcher_PauseSchedule(t *testing.T) {
+	d := setupDispatcher(&smocks.IAttackStore{})
+
+	resp, err := d.Schedule(models.ScheduleParams{Cron: "* * * * *"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = d.PauseSchedule(resp.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Paused schedules never fire
+	d.runDueSchedules(time.Now().Add(time.Hour))
+	if got, _ := d.GetSchedule(resp.ID); got.Status != models.ScheduleStatusPaused || len(got.AttackIDs) != 0 {
+		t.Fatalf("dispatcher.GetSchedule() = %v, want paused with no attacks", got)
+	}
+
+	if err = d.PauseSchedule(resp.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := d.GetSchedule(resp.ID); got.Status != models.ScheduleStatusActive {
+		t.Errorf("dispatcher.GetSchedule() = %v, want active", got)
+	}
+
+	if err = d.PauseSchedule("123", true); err == nil {
+		t.Error("dispatcher.PauseSchedule() want not found error")
+	}
+}
+
+func Test_dispatcher_DeleteSchedule(t *testing.T) {
+	d := setupDispatcher(&smocks.IAttackStore{})
+
+	resp, err := d.Schedule(models.ScheduleParams{Cron: "@daily"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = d.DeleteSchedule(resp.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = d.GetSchedule(resp.ID); err == nil {
+		t.Error("dispatcher.GetSchedule() want not found error")
+	}
+
+	if err = d.DeleteSchedule(resp.ID); err == nil {
+		t.Error("dispatcher.DeleteSchedule() want not found error")
+	}
+}
+
+func Test_dispatcher_Delete(t *testing.T) {
+	d := setupDispatcher(models.NewTaskMap())
+
+	resp, err := d.Dispatch(models.AttackParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Scheduled attacks are refused unless canceled first
+	if err = d.Delete(resp.ID, false); errors.Cause(err) != ErrActive {
+		t.Errorf("dispatcher.Delete() error = %v, want %v for a scheduled attack", err, ErrActive)
+	}
+
+	if err = d.Delete(resp.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := d.tasks[resp.ID]; ok {
+		t.Error("dispatcher.Delete() did not remove the task")
+	}
+	if _, err = d.Get(resp.ID); err == nil {
+		t.Error("dispatcher.Get() want not found error")
+	}
+	if err = d.Delete(resp.ID, true); err == nil {
+		t.Error("dispatcher.Delete() want not found error")
+	}
+}
+
+func Test_dispatcher_Delete_Restarted(t *testing.T) {
+	db := models.NewTaskMap()
+	d := setupDispatcher(db)
+
+	// The attack was completed before a restart, so it has no task
+	task := NewTask(make(chan UpdateMessage, 10), models.AttackParams{})
+	task.status = models.AttackResponseStatusRunning
+	if err := task.Complete(strings.NewReader("result")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Add(attackDetailFromTask(task)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Delete(task.ID(), false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(task.ResultPath()); !os.IsNotExist(err) {
+		t.Errorf("dispatcher.Delete() did not remove the spool file %s", task.ResultPath())
+	}
+}
+
+func Test_dispatcher_DeleteAll(t *testing.T) {
+	db := models.NewTaskMap()
+	d := setupDispatcher(db)
+
+	scheduled, err := d.Dispatch(models.AttackParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed, err := d.Dispatch(models.AttackParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := d.tasks[completed.ID].(*task)
+	task.status = models.AttackResponseStatusCompleted
+	if err = db.Update(completed.ID, attackDetailFromTask(task)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := d.DeleteAll(models.FilterParams{}, false)
+	want := models.AttackDeleteResponse{
+		Deleted: []string{completed.ID},
+		Skipped: []string{scheduled.ID},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dispatcher.DeleteAll() = %v, want %v", got, want)
+	}
+
+	got = d.DeleteAll(models.FilterParams{"status": "scheduled"}, true)
+	want = models.AttackDeleteResponse{
+		Deleted: []string{scheduled.ID},
+		Skipped: []string{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dispatcher.DeleteAll() = %v, want %v", got, want)
+	}
+	if len(d.tasks) != 0 {
+		t.Errorf("dispatcher tasks = %v, want none", d.tasks)
+	}
+}
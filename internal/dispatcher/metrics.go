@@ -0,0 +1,136 @@
+package dispatcher
+
+import (
+	"vegeta-server/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "vegeta_server"
+
+var (
+	attackRequestDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "attack", "request_duration_seconds"),
+		"Latency histogram of the requests sent by a running attack.",
+		[]string{"attack_id"}, nil,
+	)
+	attackRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "attack", "requests_total"),
+		"Number of requests sent by a running attack, by response status code.",
+		[]string{"attack_id", "code"}, nil,
+	)
+	attacksInFlightDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "attacks_in_flight"),
+		"Number of attacks currently running.",
+		nil, nil,
+	)
+	attacksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "attacks"),
+		"Number of attacks tracked by the dispatcher, by status.",
+		[]string{"status"}, nil,
+	)
+	queueLengthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "dispatcher", "queue_length"),
+		"Number of messages waiting in a dispatcher queue.",
+		[]string{"queue"}, nil,
+	)
+	retentionEvictedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "retention", "evicted_attacks_total"),
+		"Number of ended attacks evicted by the retention policy.",
+		nil, nil,
+	)
+	retentionEvictedBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "retention", "evicted_bytes_total"),
+		"Size of the attack results evicted by the retention policy.",
+		nil, nil,
+	)
+)
+
+// attackStatuses lists all statuses, so that a count is exported for each of them
+var attackStatuses = []models.AttackStatus{
+	models.AttackResponseStatusScheduled,
+	models.AttackResponseStatusRunning,
+	models.AttackResponseStatusCanceled,
+	models.AttackResponseStatusCompleted,
+	models.AttackResponseStatusFailed,
+}
+
+// Describe implements the prometheus.Collector interface
+func (d *dispatcher) Describe(ch chan<- *prometheus.Desc) {
+	ch <- attackRequestDurationDesc
+	ch <- attackRequestsDesc
+	ch <- attacksInFlightDesc
+	ch <- attacksDesc
+	ch <- queueLengthDesc
+	ch <- retentionEvictedDesc
+	ch <- retentionEvictedBytesDesc
+}
+
+// Collect implements the prometheus.Collector interface. The metrics are
+// computed from the tracked tasks and dispatcher queues at scrape time.
+func (d *dispatcher) Collect(ch chan<- prometheus.Metric) {
+	counts := make(map[models.AttackStatus]int)
+
+	d.mu.RLock()
+	tasks := make([]ITask, 0, len(d.tasks))
+	for _, t := range d.tasks {
+		tasks = append(tasks, t)
+	}
+	evicted, evictedBytes := d.retention.evicted, d.retention.evictedBytes
+	d.mu.RUnlock()
+
+	for _, t := range tasks {
+		status := t.Status()
+		counts[status]++
+
+		// Only running attacks export their own series, so that the number
+		// of series does not grow with every attack ever run
+		if status == models.AttackResponseStatusRunning {
+			collectAttackMetrics(ch, t.Metrics())
+		}
+	}
+
+	for _, status := range attackStatuses {
+		ch <- prometheus.MustNewConstMetric(
+			attacksDesc, prometheus.GaugeValue, float64(counts[status]), string(status),
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		attacksInFlightDesc, prometheus.GaugeValue, float64(counts[models.AttackResponseStatusRunning]),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		queueLengthDesc, prometheus.GaugeValue, float64(len(d.submitCh)), "submit",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		queueLengthDesc, prometheus.GaugeValue, float64(len(d.updateCh)), "update",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		retentionEvictedDesc, prometheus.CounterValue, float64(evicted),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		retentionEvictedBytesDesc, prometheus.CounterValue, float64(evictedBytes),
+	)
+}
+
+func collectAttackMetrics(ch chan<- prometheus.Metric, m models.AttackMetrics) {
+	if m.Requests == 0 {
+		return
+	}
+
+	buckets := make(map[float64]uint64, len(m.Buckets))
+	for _, b := range m.Buckets {
+		buckets[b.UpperBound] = b.Count
+	}
+
+	sum := float64(m.Latencies.Total) / 1e9
+	ch <- prometheus.MustNewConstHistogram(
+		attackRequestDurationDesc, m.Requests, sum, buckets, m.ID,
+	)
+
+	for code, count := range m.StatusCodes {
+		ch <- prometheus.MustNewConstMetric(
+			attackRequestsDesc, prometheus.CounterValue, float64(count), m.ID, code,
+		)
+	}
+}
@@ -0,0 +1,73 @@
+package dispatcher
+
+import (
+	"strings"
+	"testing"
+	"vegeta-server/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_dispatcher_Collect(t *testing.T) {
+	d := setupDispatcher(nil)
+
+	running := NewTask(d.updateCh, models.AttackParams{})
+	running.status = models.AttackResponseStatusRunning
+	running.id = "1"
+	running.metrics = models.AttackMetrics{
+		ID:       "1",
+		Requests: 3,
+		Buckets: []models.LatencyBucket{
+			{UpperBound: 0.5, Count: 1},
+			{UpperBound: 1, Count: 2},
+		},
+		StatusCodes: map[string]int{"200": 2, "500": 1},
+	}
+	running.metrics.Latencies.Total = 3e9
+
+	completed := NewTask(d.updateCh, models.AttackParams{})
+	completed.status = models.AttackResponseStatusCompleted
+	completed.metrics = running.metrics
+	completed.metrics.ID = completed.ID()
+
+	d.tasks[running.ID()] = running
+	d.tasks[completed.ID()] = completed
+
+	want := `
+# HELP vegeta_server_attack_request_duration_seconds Latency histogram of the requests sent by a running attack.
+# TYPE vegeta_server_attack_request_duration_seconds histogram
+vegeta_server_attack_request_duration_seconds_bucket{attack_id="1",le="0.5"} 1
+vegeta_server_attack_request_duration_seconds_bucket{attack_id="1",le="1"} 2
+vegeta_server_attack_request_duration_seconds_bucket{attack_id="1",le="+Inf"} 3
+vegeta_server_attack_request_duration_seconds_sum{attack_id="1"} 3
+vegeta_server_attack_request_duration_seconds_count{attack_id="1"} 3
+# HELP vegeta_server_attack_requests_total Number of requests sent by a running attack, by response status code.
+# TYPE vegeta_server_attack_requests_total counter
+vegeta_server_attack_requests_total{attack_id="1",code="200"} 2
+vegeta_server_attack_requests_total{attack_id="1",code="500"} 1
+# HELP vegeta_server_attacks Number of attacks tracked by the dispatcher, by status.
+# TYPE vegeta_server_attacks gauge
+vegeta_server_attacks{status="canceled"} 0
+vegeta_server_attacks{status="completed"} 1
+vegeta_server_attacks{status="failed"} 0
+vegeta_server_attacks{status="running"} 1
+vegeta_server_attacks{status="scheduled"} 0
+# HELP vegeta_server_attacks_in_flight Number of attacks currently running.
+# TYPE vegeta_server_attacks_in_flight gauge
+vegeta_server_attacks_in_flight 1
+# HELP vegeta_server_dispatcher_queue_length Number of messages waiting in a dispatcher queue.
+# TYPE vegeta_server_dispatcher_queue_length gauge
+vegeta_server_dispatcher_queue_length{queue="submit"} 0
+vegeta_server_dispatcher_queue_length{queue="update"} 0
+# HELP vegeta_server_retention_evicted_attacks_total Number of ended attacks evicted by the retention policy.
+# TYPE vegeta_server_retention_evicted_attacks_total counter
+vegeta_server_retention_evicted_attacks_total 0
+# HELP vegeta_server_retention_evicted_bytes_total Size of the attack results evicted by the retention policy.
+# TYPE vegeta_server_retention_evicted_bytes_total counter
+vegeta_server_retention_evicted_bytes_total 0
+`
+
+	if err := testutil.CollectAndCompare(d, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
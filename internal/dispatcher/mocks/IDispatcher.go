@@ -11,7 +11,21 @@ type IDispatcher struct {
 }
 
 // Cancel provides a mock function with given fields: _a0, _a1
-func (_m *IDispatcher) Cancel(_a0 string, _a1 bool) error {
+func (_m *IDispatcher) Cancel(_a0 string, _a1 models.AttackCancel) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, models.AttackCancel) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: _a0, _a1
+func (_m *IDispatcher) Delete(_a0 string, _a1 bool) error {
 	ret := _m.Called(_a0, _a1)
 
 	var r0 error
@@ -24,6 +38,57 @@ func (_m *IDispatcher) Cancel(_a0 string, _a1 bool) error {
 	return r0
 }
 
+// DeleteAll provides a mock function with given fields: _a0, _a1
+func (_m *IDispatcher) DeleteAll(_a0 models.FilterParams, _a1 bool) models.AttackDeleteResponse {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 models.AttackDeleteResponse
+	if rf, ok := ret.Get(0).(func(models.FilterParams, bool) models.AttackDeleteResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(models.AttackDeleteResponse)
+	}
+
+	return r0
+}
+
+// DeleteSchedule provides a mock function with given fields: _a0
+func (_m *IDispatcher) DeleteSchedule(_a0 string) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Deliveries provides a mock function with given fields: _a0
+func (_m *IDispatcher) Deliveries(_a0 string) ([]models.WebhookDelivery, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []models.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(string) []models.WebhookDelivery); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.WebhookDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Dispatch provides a mock function with given fields: _a0
 func (_m *IDispatcher) Dispatch(_a0 models.AttackParams) (*models.AttackResponse, error) {
 	ret := _m.Called(_a0)
@@ -70,23 +135,164 @@ func (_m *IDispatcher) Get(_a0 string) (*models.AttackResponse, error) {
 	return r0, r1
 }
 
-// List provides a mock function with given fields: _a0
-func (_m *IDispatcher) List(_a0 models.FilterParams) []*models.AttackResponse {
+// GetSchedule provides a mock function with given fields: _a0
+func (_m *IDispatcher) GetSchedule(_a0 string) (*models.ScheduleResponse, error) {
 	ret := _m.Called(_a0)
 
-	var r0 []*models.AttackResponse
-	if rf, ok := ret.Get(0).(func(models.FilterParams) []*models.AttackResponse); ok {
+	var r0 *models.ScheduleResponse
+	if rf, ok := ret.Get(0).(func(string) *models.ScheduleResponse); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.AttackResponse)
+			r0 = ret.Get(0).(*models.ScheduleResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: _a0, _a1
+func (_m *IDispatcher) List(_a0 models.FilterParams, _a1 models.ListParams) (models.AttackList, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 models.AttackList
+	if rf, ok := ret.Get(0).(func(models.FilterParams, models.ListParams) models.AttackList); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(models.AttackList)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.FilterParams, models.ListParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSchedules provides a mock function with given fields:
+func (_m *IDispatcher) ListSchedules() []*models.ScheduleResponse {
+	ret := _m.Called()
+
+	var r0 []*models.ScheduleResponse
+	if rf, ok := ret.Get(0).(func() []*models.ScheduleResponse); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ScheduleResponse)
 		}
 	}
 
 	return r0
 }
 
+// PauseSchedule provides a mock function with given fields: _a0, _a1
+func (_m *IDispatcher) PauseSchedule(_a0 string, _a1 bool) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, bool) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Queue provides a mock function with given fields:
+func (_m *IDispatcher) Queue() models.QueueResponse {
+	ret := _m.Called()
+
+	var r0 models.QueueResponse
+	if rf, ok := ret.Get(0).(func() models.QueueResponse); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(models.QueueResponse)
+	}
+
+	return r0
+}
+
+// Retention provides a mock function with given fields:
+func (_m *IDispatcher) Retention() models.RetentionResponse {
+	ret := _m.Called()
+
+	var r0 models.RetentionResponse
+	if rf, ok := ret.Get(0).(func() models.RetentionResponse); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(models.RetentionResponse)
+	}
+
+	return r0
+}
+
 // Run provides a mock function with given fields: _a0
 func (_m *IDispatcher) Run(_a0 chan struct{}) {
 	_m.Called(_a0)
 }
+
+// Schedule provides a mock function with given fields: _a0
+func (_m *IDispatcher) Schedule(_a0 models.ScheduleParams) (*models.ScheduleResponse, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *models.ScheduleResponse
+	if rf, ok := ret.Get(0).(func(models.ScheduleParams) *models.ScheduleResponse); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ScheduleResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.ScheduleParams) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Stream provides a mock function with given fields: _a0
+func (_m *IDispatcher) Stream(_a0 string) (<-chan models.AttackMetrics, func(), error) {
+	ret := _m.Called(_a0)
+
+	var r0 <-chan models.AttackMetrics
+	if rf, ok := ret.Get(0).(func(string) <-chan models.AttackMetrics); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan models.AttackMetrics)
+		}
+	}
+
+	var r1 func()
+	if rf, ok := ret.Get(1).(func(string) func()); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(_a0)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
@@ -13,13 +13,13 @@ type ITask struct {
 	mock.Mock
 }
 
-// Cancel provides a mock function with given fields:
-func (_m *ITask) Cancel() error {
-	ret := _m.Called()
+// Cancel provides a mock function with given fields: _a0, _a1
+func (_m *ITask) Cancel(_a0 string, _a1 string) error {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -27,6 +27,22 @@ func (_m *ITask) Cancel() error {
 	return r0
 }
 
+// Cancellation provides a mock function with given fields:
+func (_m *ITask) Cancellation() *models.AttackCancellation {
+	ret := _m.Called()
+
+	var r0 *models.AttackCancellation
+	if rf, ok := ret.Get(0).(func() *models.AttackCancellation); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AttackCancellation)
+		}
+	}
+
+	return r0
+}
+
 // Complete provides a mock function with given fields: _a0
 func (_m *ITask) Complete(_a0 io.Reader) error {
 	ret := _m.Called(_a0)
@@ -55,8 +71,8 @@ func (_m *ITask) CreatedAt() time.Time {
 	return r0
 }
 
-// Fail provides a mock function with given fields:
-func (_m *ITask) Fail() error {
+// Discard provides a mock function with given fields:
+func (_m *ITask) Discard() error {
 	ret := _m.Called()
 
 	var r0 error
@@ -69,6 +85,36 @@ func (_m *ITask) Fail() error {
 	return r0
 }
 
+// Error provides a mock function with given fields:
+func (_m *ITask) Error() *models.AttackError {
+	ret := _m.Called()
+
+	var r0 *models.AttackError
+	if rf, ok := ret.Get(0).(func() *models.AttackError); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AttackError)
+		}
+	}
+
+	return r0
+}
+
+// Fail provides a mock function with given fields: _a0
+func (_m *ITask) Fail(_a0 error) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(error) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ID provides a mock function with given fields:
 func (_m *ITask) ID() string {
 	ret := _m.Called()
@@ -83,6 +129,20 @@ func (_m *ITask) ID() string {
 	return r0
 }
 
+// Metrics provides a mock function with given fields:
+func (_m *ITask) Metrics() models.AttackMetrics {
+	ret := _m.Called()
+
+	var r0 models.AttackMetrics
+	if rf, ok := ret.Get(0).(func() models.AttackMetrics); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(models.AttackMetrics)
+	}
+
+	return r0
+}
+
 // Params provides a mock function with given fields:
 func (_m *ITask) Params() models.AttackParams {
 	ret := _m.Called()
@@ -113,6 +173,20 @@ func (_m *ITask) Result() io.Reader {
 	return r0
 }
 
+// ResultPath provides a mock function with given fields:
+func (_m *ITask) ResultPath() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // Run provides a mock function with given fields: _a0
 func (_m *ITask) Run(_a0 dispatcher.AttackFunc) error {
 	ret := _m.Called(_a0)
@@ -127,6 +201,20 @@ func (_m *ITask) Run(_a0 dispatcher.AttackFunc) error {
 	return r0
 }
 
+// ScheduleID provides a mock function with given fields:
+func (_m *ITask) ScheduleID() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // SendUpdate provides a mock function with given fields:
 func (_m *ITask) SendUpdate() {
 	_m.Called()
@@ -146,6 +234,38 @@ func (_m *ITask) Status() models.AttackStatus {
 	return r0
 }
 
+// Subscribe provides a mock function with given fields:
+func (_m *ITask) Subscribe() (<-chan models.AttackMetrics, func(), error) {
+	ret := _m.Called()
+
+	var r0 <-chan models.AttackMetrics
+	if rf, ok := ret.Get(0).(func() <-chan models.AttackMetrics); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan models.AttackMetrics)
+		}
+	}
+
+	var r1 func()
+	if rf, ok := ret.Get(1).(func() func()); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func() error); ok {
+		r2 = rf()
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // UpdatedAt provides a mock function with given fields:
 func (_m *ITask) UpdatedAt() time.Time {
 	ret := _m.Called()
@@ -159,3 +279,19 @@ func (_m *ITask) UpdatedAt() time.Time {
 
 	return r0
 }
+
+// Verdict provides a mock function with given fields:
+func (_m *ITask) Verdict() *models.Verdict {
+	ret := _m.Called()
+
+	var r0 *models.Verdict
+	if rf, ok := ret.Get(0).(func() *models.Verdict); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Verdict)
+		}
+	}
+
+	return r0
+}
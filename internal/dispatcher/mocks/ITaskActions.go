@@ -5,19 +5,20 @@ package mocks
 import dispatcher "vegeta-server/internal/dispatcher"
 import io "io"
 import mock "github.com/stretchr/testify/mock"
+import models "vegeta-server/models"
 
 // ITaskActions is an autogenerated mock type for the ITaskActions type
 type ITaskActions struct {
 	mock.Mock
 }
 
-// Cancel provides a mock function with given fields:
-func (_m *ITaskActions) Cancel() error {
-	ret := _m.Called()
+// Cancel provides a mock function with given fields: _a0, _a1
+func (_m *ITaskActions) Cancel(_a0 string, _a1 string) error {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -39,8 +40,8 @@ func (_m *ITaskActions) Complete(_a0 io.Reader) error {
 	return r0
 }
 
-// Fail provides a mock function with given fields:
-func (_m *ITaskActions) Fail() error {
+// Discard provides a mock function with given fields:
+func (_m *ITaskActions) Discard() error {
 	ret := _m.Called()
 
 	var r0 error
@@ -53,6 +54,20 @@ func (_m *ITaskActions) Fail() error {
 	return r0
 }
 
+// Fail provides a mock function with given fields: _a0
+func (_m *ITaskActions) Fail(_a0 error) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(error) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Run provides a mock function with given fields: _a0
 func (_m *ITaskActions) Run(_a0 dispatcher.AttackFunc) error {
 	ret := _m.Called(_a0)
@@ -71,3 +86,35 @@ func (_m *ITaskActions) Run(_a0 dispatcher.AttackFunc) error {
 func (_m *ITaskActions) SendUpdate() {
 	_m.Called()
 }
+
+// Subscribe provides a mock function with given fields:
+func (_m *ITaskActions) Subscribe() (<-chan models.AttackMetrics, func(), error) {
+	ret := _m.Called()
+
+	var r0 <-chan models.AttackMetrics
+	if rf, ok := ret.Get(0).(func() <-chan models.AttackMetrics); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan models.AttackMetrics)
+		}
+	}
+
+	var r1 func()
+	if rf, ok := ret.Get(1).(func() func()); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func() error); ok {
+		r2 = rf()
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
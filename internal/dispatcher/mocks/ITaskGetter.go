@@ -12,6 +12,22 @@ type ITaskGetter struct {
 	mock.Mock
 }
 
+// Cancellation provides a mock function with given fields:
+func (_m *ITaskGetter) Cancellation() *models.AttackCancellation {
+	ret := _m.Called()
+
+	var r0 *models.AttackCancellation
+	if rf, ok := ret.Get(0).(func() *models.AttackCancellation); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AttackCancellation)
+		}
+	}
+
+	return r0
+}
+
 // CreatedAt provides a mock function with given fields:
 func (_m *ITaskGetter) CreatedAt() time.Time {
 	ret := _m.Called()
@@ -26,6 +42,22 @@ func (_m *ITaskGetter) CreatedAt() time.Time {
 	return r0
 }
 
+// Error provides a mock function with given fields:
+func (_m *ITaskGetter) Error() *models.AttackError {
+	ret := _m.Called()
+
+	var r0 *models.AttackError
+	if rf, ok := ret.Get(0).(func() *models.AttackError); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AttackError)
+		}
+	}
+
+	return r0
+}
+
 // ID provides a mock function with given fields:
 func (_m *ITaskGetter) ID() string {
 	ret := _m.Called()
@@ -40,6 +72,20 @@ func (_m *ITaskGetter) ID() string {
 	return r0
 }
 
+// Metrics provides a mock function with given fields:
+func (_m *ITaskGetter) Metrics() models.AttackMetrics {
+	ret := _m.Called()
+
+	var r0 models.AttackMetrics
+	if rf, ok := ret.Get(0).(func() models.AttackMetrics); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(models.AttackMetrics)
+	}
+
+	return r0
+}
+
 // Params provides a mock function with given fields:
 func (_m *ITaskGetter) Params() models.AttackParams {
 	ret := _m.Called()
@@ -70,6 +116,34 @@ func (_m *ITaskGetter) Result() io.Reader {
 	return r0
 }
 
+// ResultPath provides a mock function with given fields:
+func (_m *ITaskGetter) ResultPath() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ScheduleID provides a mock function with given fields:
+func (_m *ITaskGetter) ScheduleID() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // Status provides a mock function with given fields:
 func (_m *ITaskGetter) Status() models.AttackStatus {
 	ret := _m.Called()
@@ -97,3 +171,19 @@ func (_m *ITaskGetter) UpdatedAt() time.Time {
 
 	return r0
 }
+
+// Verdict provides a mock function with given fields:
+func (_m *ITaskGetter) Verdict() *models.Verdict {
+	ret := _m.Called()
+
+	var r0 *models.Verdict
+	if rf, ok := ret.Get(0).(func() *models.Verdict); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Verdict)
+		}
+	}
+
+	return r0
+}
@@ -0,0 +1,148 @@
+package dispatcher
+
+import (
+	"time"
+	"vegeta-server/models"
+)
+
+// QueueOptions configures the queue of attacks waiting to run
+type QueueOptions struct {
+	// MaxConcurrent is the maximum number of attacks running at once, zero
+	// for no limit
+	MaxConcurrent int
+	// Fairness runs the attacks of the users with the fewest running attacks
+	// first, among attacks of the same priority
+	Fairness bool
+}
+
+// queue holds the attacks waiting to run. Attacks run by priority, then
+// optionally by user fairness, then in submission order.
+type queue struct {
+	opts    QueueOptions
+	waiting []ITask
+	// running maps the ID of each running attack to its user
+	running map[string]string
+}
+
+func newQueue(opts QueueOptions) *queue {
+	return &queue{
+		opts,
+		make([]ITask, 0),
+		make(map[string]string),
+	}
+}
+
+// push an attack at the back of the queue
+func (q *queue) push(t ITask) {
+	q.waiting = append(q.waiting, t)
+}
+
+// remove an attack from the queue, whether waiting or running
+func (q *queue) remove(id string) {
+	delete(q.running, id)
+
+	for i, t := range q.waiting {
+		if t.ID() == id {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+// next pops the attacks that can start running now, and marks them as
+// running
+func (q *queue) next() []ITask {
+	tasks := make([]ITask, 0)
+	for len(q.waiting) > 0 && (q.opts.MaxConcurrent == 0 || len(q.running) < q.opts.MaxConcurrent) {
+		i := q.pick(q.waiting, q.perUser())
+		t := q.waiting[i]
+		q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+
+		q.running[t.ID()] = t.Params().User
+		tasks = append(tasks, t)
+	}
+	return tasks
+}
+
+// order returns the waiting attacks in the order they are expected to run,
+// assuming no other attack is submitted or ends
+func (q *queue) order() []ITask {
+	waiting := append([]ITask{}, q.waiting...)
+	perUser := q.perUser()
+
+	ordered := make([]ITask, 0, len(waiting))
+	for len(waiting) > 0 {
+		i := q.pick(waiting, perUser)
+		t := waiting[i]
+		waiting = append(waiting[:i], waiting[i+1:]...)
+
+		perUser[t.Params().User]++
+		ordered = append(ordered, t)
+	}
+	return ordered
+}
+
+// position returns the 1-based position of a waiting attack, zero if the
+// attack is not waiting
+func (q *queue) position(id string) int {
+	for i, t := range q.order() {
+		if t.ID() == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// response returns the state of the queue
+func (q *queue) response() models.QueueResponse {
+	resp := models.QueueResponse{
+		MaxConcurrent: q.opts.MaxConcurrent,
+		Fairness:      q.opts.Fairness,
+		Running:       len(q.running),
+		Queued:        make([]models.QueueEntry, 0, len(q.waiting)),
+	}
+
+	for i, t := range q.order() {
+		params := t.Params()
+		resp.Queued = append(resp.Queued, models.QueueEntry{
+			ID:        t.ID(),
+			Position:  i + 1,
+			Priority:  params.Priority,
+			User:      params.User,
+			CreatedAt: t.CreatedAt().Format(time.RFC1123),
+		})
+	}
+	return resp
+}
+
+// pick returns the index of the attack to run next
+func (q *queue) pick(waiting []ITask, perUser map[string]int) int {
+	best := 0
+	for i := 1; i < len(waiting); i++ {
+		if q.before(waiting[i], waiting[best], perUser) {
+			best = i
+		}
+	}
+	return best
+}
+
+// before reports whether attack a runs before attack b. Attacks earlier in
+// the queue run first when they are otherwise equal.
+func (q *queue) before(a, b ITask, perUser map[string]int) bool {
+	pa, pb := a.Params(), b.Params()
+	if pa.Priority != pb.Priority {
+		return pa.Priority > pb.Priority
+	}
+	if q.opts.Fairness {
+		return perUser[pa.User] < perUser[pb.User]
+	}
+	return false
+}
+
+func (q *queue) perUser() map[string]int {
+	perUser := make(map[string]int)
+	for _, user := range q.running {
+		perUser[user]++
+	}
+	return perUser
+}
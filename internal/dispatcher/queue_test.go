@@ -0,0 +1,92 @@
+package dispatcher
+
+import (
+	"reflect"
+	"testing"
+	"vegeta-server/models"
+)
+
+func queuedTask(priority int, user string) *task {
+	return NewTask(make(chan UpdateMessage, 10), models.AttackParams{Priority: priority, User: user})
+}
+
+func ids(tasks []ITask) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.ID())
+	}
+	return ids
+}
+
+func Test_queue_next(t *testing.T) {
+	q := newQueue(QueueOptions{MaxConcurrent: 2})
+
+	a, b, c, d := queuedTask(0, ""), queuedTask(0, ""), queuedTask(1, ""), queuedTask(0, "")
+	for _, task := range []*task{a, b, c, d} {
+		q.push(task)
+	}
+
+	// The high priority attack runs first, then in submission order
+	if got, want := ids(q.next()), []string{c.ID(), a.ID()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("next() = %v, want %v", got, want)
+	}
+	if got := q.next(); len(got) != 0 {
+		t.Errorf("next() = %v, want none while at the concurrency limit", ids(got))
+	}
+	if got, want := q.position(d.ID()), 2; got != want {
+		t.Errorf("position() = %v, want %v", got, want)
+	}
+
+	// Ended and canceled attacks make room
+	q.remove(a.ID())
+	q.remove(b.ID())
+	if got, want := ids(q.next()), []string{d.ID()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("next() = %v, want %v", got, want)
+	}
+	if got := q.position(d.ID()); got != 0 {
+		t.Errorf("position() = %v, want 0 once running", got)
+	}
+}
+
+func Test_queue_order_Fairness(t *testing.T) {
+	tests := []struct {
+		name     string
+		fairness bool
+		want     []int
+	}{
+		{name: "FIFO", fairness: false, want: []int{0, 1, 2, 3}},
+		{name: "Fair", fairness: true, want: []int{2, 0, 3, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newQueue(QueueOptions{MaxConcurrent: 1, Fairness: tt.fairness})
+
+			// alice already runs an attack
+			q.push(queuedTask(0, "alice"))
+			q.next()
+
+			waiting := []*task{
+				queuedTask(0, "alice"),
+				queuedTask(0, "alice"),
+				queuedTask(0, "bob"),
+				queuedTask(0, "bob"),
+			}
+			for _, task := range waiting {
+				q.push(task)
+			}
+
+			want := make([]string, 0, len(tt.want))
+			for _, i := range tt.want {
+				want = append(want, waiting[i].ID())
+			}
+			if got := ids(q.order()); !reflect.DeepEqual(got, want) {
+				t.Errorf("order() = %v, want %v", got, want)
+			}
+
+			resp := q.response()
+			if resp.Running != 1 || len(resp.Queued) != 4 || resp.Queued[0].ID != want[0] || resp.Queued[0].Position != 1 {
+				t.Errorf("response() = %v", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package dispatcher
+
+import (
+	"sort"
+	"time"
+	"vegeta-server/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// retentionInterval is the interval at which ended attacks are swept
+var retentionInterval = time.Minute
+
+// RetentionPolicy configures the eviction of ended attacks. Completed,
+// failed and canceled attacks are evicted oldest first, once any limit is
+// exceeded. Zero values are unlimited.
+type RetentionPolicy struct {
+	// MaxAge is the time ended attacks are kept for
+	MaxAge time.Duration
+	// MaxCount is the maximum number of attacks kept
+	MaxCount int
+	// MaxBytes is the maximum size of the attack results kept
+	MaxBytes int64
+}
+
+func (p RetentionPolicy) enabled() bool {
+	return p.MaxAge > 0 || p.MaxCount > 0 || p.MaxBytes > 0
+}
+
+// retention tracks the attacks evicted by the retention policy
+type retention struct {
+	policy       RetentionPolicy
+	evicted      uint64
+	evictedBytes uint64
+	lastSweep    *models.RetentionSweep
+	// sweeping is set while a sweep runs
+	sweeping bool
+}
+
+// Retention returns the retention policy and the attacks it evicted
+func (d *dispatcher) Retention() models.RetentionResponse {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	resp := models.RetentionResponse{
+		MaxCount:          d.retention.policy.MaxCount,
+		MaxBytes:          d.retention.policy.MaxBytes,
+		EvictedTotal:      d.retention.evicted,
+		EvictedBytesTotal: d.retention.evictedBytes,
+		LastSweep:         d.retention.lastSweep,
+	}
+	if d.retention.policy.MaxAge > 0 {
+		resp.MaxAge = d.retention.policy.MaxAge.String()
+	}
+
+	return resp
+}
+
+// startSweep sweeps the ended attacks off the event loop, as deleting attacks
+// may start queued attacks, which send updates to the event loop. No sweep is
+// started while the previous one still runs.
+func (d *dispatcher) startSweep(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.retention.sweeping {
+		d.log(nil).Warn("skipping sweep, previous sweep still running")
+		return
+	}
+	d.retention.sweeping = true
+
+	go func() {
+		d.sweep(now)
+
+		d.mu.Lock()
+		d.retention.sweeping = false
+		d.mu.Unlock()
+	}()
+}
+
+// sweep evicts the ended attacks exceeding the retention policy, oldest
+// first
+func (d *dispatcher) sweep(now time.Time) models.RetentionSweep {
+	policy := d.retention.policy
+	sweep := models.RetentionSweep{
+		Timestamp: now.Format(time.RFC1123),
+		Evicted:   make([]string, 0),
+	}
+
+	attacks := d.db.GetAll(make(models.FilterParams))
+	count := len(attacks)
+	var size int64
+	ended := make([]models.AttackDetails, 0, len(attacks))
+	for _, attack := range attacks {
+		size += attack.ResultLen()
+		if !active(attack.Status) {
+			ended = append(ended, attack)
+		}
+	}
+
+	sort.Slice(ended, func(i, j int) bool {
+		ti, tj := endedAt(ended[i]), endedAt(ended[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return ended[i].ID < ended[j].ID
+	})
+
+	for _, attack := range ended {
+		expired := policy.MaxAge > 0 && now.Sub(endedAt(attack)) > policy.MaxAge
+		tooMany := policy.MaxCount > 0 && count > policy.MaxCount
+		tooBig := policy.MaxBytes > 0 && size > policy.MaxBytes
+		if !expired && !tooMany && !tooBig {
+			break
+		}
+
+		if err := d.Delete(attack.ID, false); err != nil {
+			d.log(log.Fields{"ID": attack.ID}).WithError(err).Error("failed to evict attack")
+			continue
+		}
+
+		count--
+		size -= attack.ResultLen()
+		sweep.Evicted = append(sweep.Evicted, attack.ID)
+		sweep.FreedBytes += attack.ResultLen()
+	}
+
+	d.mu.Lock()
+	d.retention.evicted += uint64(len(sweep.Evicted))
+	d.retention.evictedBytes += uint64(sweep.FreedBytes)
+	d.retention.lastSweep = &sweep
+	d.mu.Unlock()
+
+	if len(sweep.Evicted) > 0 {
+		d.log(log.Fields{
+			"Evicted":    len(sweep.Evicted),
+			"FreedBytes": sweep.FreedBytes,
+		}).Info("evicted attacks exceeding the retention policy")
+	}
+
+	return sweep
+}
+
+// endedAt returns the time an ended attack was last updated
+func endedAt(attack models.AttackDetails) time.Time {
+	t, _ := time.Parse(time.RFC1123, attack.UpdatedAt)
+	return t
+}
@@ -0,0 +1,109 @@
+package dispatcher
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"vegeta-server/models"
+)
+
+func Test_dispatcher_sweep(t *testing.T) {
+	now := time.Now()
+	attack := func(id string, status models.AttackStatus, age time.Duration, size int) models.AttackDetails {
+		return models.AttackDetails{
+			AttackInfo: models.AttackInfo{
+				ID:        id,
+				Status:    status,
+				UpdatedAt: now.Add(-age).Format(time.RFC1123),
+			},
+			Result: make([]byte, size),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []string
+	}{
+		{
+			name:   "Max Age",
+			policy: RetentionPolicy{MaxAge: time.Hour},
+			want:   []string{"failed", "completed"},
+		},
+		{
+			name:   "Max Count",
+			policy: RetentionPolicy{MaxCount: 4},
+			want:   []string{"failed"},
+		},
+		{
+			name:   "Max Bytes",
+			policy: RetentionPolicy{MaxBytes: 100},
+			want:   []string{"failed", "completed"},
+		},
+		{
+			name:   "Within Limits",
+			policy: RetentionPolicy{MaxAge: 24 * time.Hour, MaxCount: 10, MaxBytes: 1000},
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := models.NewTaskMap()
+			for _, a := range []models.AttackDetails{
+				attack("running", models.AttackResponseStatusRunning, 5*time.Hour, 0),
+				attack("failed", models.AttackResponseStatusFailed, 3*time.Hour, 0),
+				attack("completed", models.AttackResponseStatusCompleted, 2*time.Hour, 60),
+				attack("canceled", models.AttackResponseStatusCanceled, 30*time.Minute, 0),
+				attack("recent", models.AttackResponseStatusCompleted, time.Minute, 60),
+			} {
+				_ = db.Add(a)
+			}
+
+			d := setupDispatcher(db)
+			d.retention.policy = tt.policy
+
+			got := d.sweep(now)
+			if !reflect.DeepEqual(got.Evicted, tt.want) {
+				t.Errorf("dispatcher.sweep() evicted %v, want %v", got.Evicted, tt.want)
+			}
+
+			for _, id := range tt.want {
+				if _, err := db.GetByID(id); err == nil {
+					t.Errorf("attack %s was not deleted", id)
+				}
+			}
+			if _, err := db.GetByID("running"); err != nil {
+				t.Error("running attack was evicted")
+			}
+
+			resp := d.Retention()
+			if resp.EvictedTotal != uint64(len(tt.want)) || resp.LastSweep == nil {
+				t.Errorf("dispatcher.Retention() = %v", resp)
+			}
+		})
+	}
+}
+
+func Test_dispatcher_startSweep(t *testing.T) {
+	d := setupDispatcher(models.NewTaskMap())
+	d.retention.policy = RetentionPolicy{MaxCount: 1}
+
+	// No sweep starts while the previous one still runs
+	d.retention.sweeping = true
+	d.startSweep(time.Now())
+	time.Sleep(50 * time.Millisecond)
+	if resp := d.Retention(); resp.LastSweep != nil {
+		t.Fatalf("sweep ran while the previous sweep was running")
+	}
+
+	d.retention.sweeping = false
+	d.startSweep(time.Now())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for d.Retention().LastSweep == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for sweep")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,147 @@
+package dispatcher
+
+import (
+	"fmt"
+	"time"
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+	uuid "github.com/satori/go.uuid"
+)
+
+// scheduleInterval is the interval at which the dispatcher checks for due
+// schedules.
+var scheduleInterval = time.Second
+
+// schedule holds the params of an attack submitted with a start time or a
+// cron expression. A new attack is created every time the schedule fires.
+type schedule struct {
+	id     string
+	params models.ScheduleParams
+	status models.ScheduleStatus
+
+	// startAt is the time before which the schedule never fires
+	startAt time.Time
+	// cron is nil for schedules that fire once, at startAt
+	cron cron.Schedule
+	// next is the time the schedule fires next, zero once it is done
+	next time.Time
+
+	attackIDs []string
+
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// newSchedule validates the schedule params and returns a new active schedule
+func newSchedule(params models.ScheduleParams, now time.Time) (*schedule, error) {
+	if params.StartAt == "" && params.Cron == "" {
+		return nil, fmt.Errorf("one of start_at or cron is required")
+	}
+
+	s := &schedule{
+		id:        uuid.NewV4().String(),
+		params:    params,
+		status:    models.ScheduleStatusActive,
+		startAt:   now,
+		attackIDs: make([]string, 0),
+		createdAt: now,
+		updatedAt: now,
+	}
+
+	if params.StartAt != "" {
+		startAt, err := time.Parse(time.RFC3339, params.StartAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse start_at")
+		}
+		s.startAt = startAt
+	}
+
+	if params.Cron != "" {
+		c, err := cron.ParseStandard(params.Cron)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse cron expression")
+		}
+		s.cron = c
+	}
+
+	s.next = s.nextAfter(now)
+	if s.next.IsZero() {
+		return nil, fmt.Errorf("cron expression %q never fires", params.Cron)
+	}
+
+	return s, nil
+}
+
+// nextAfter returns the time the schedule fires next, after t. Firings missed
+// while the schedule was paused are skipped.
+func (s *schedule) nextAfter(t time.Time) time.Time {
+	if s.cron == nil {
+		return s.startAt
+	}
+
+	if t.Before(s.startAt) {
+		// Include a firing that falls exactly on startAt
+		t = s.startAt.Add(-time.Second)
+	}
+
+	return s.cron.Next(t)
+}
+
+// due returns true if the schedule is active and its next firing has passed
+func (s *schedule) due(now time.Time) bool {
+	return s.status == models.ScheduleStatusActive && !s.next.IsZero() && !now.Before(s.next)
+}
+
+// fired records an attack created by the schedule, and moves the schedule on
+// to its next firing. Schedules without a cron expression are then done.
+func (s *schedule) fired(attackID string, now time.Time) {
+	s.attackIDs = append(s.attackIDs, attackID)
+	s.updatedAt = now
+
+	if s.cron == nil {
+		s.status = models.ScheduleStatusDone
+		s.next = time.Time{}
+		return
+	}
+
+	s.next = s.cron.Next(now)
+	if s.next.IsZero() {
+		s.status = models.ScheduleStatusDone
+	}
+}
+
+// pause stops the schedule from firing, or resumes a paused schedule
+func (s *schedule) pause(pause bool, now time.Time) error {
+	if s.status == models.ScheduleStatusDone {
+		return fmt.Errorf("cannot pause or resume schedule %s with status %s", s.id, s.status)
+	}
+
+	if pause {
+		s.status = models.ScheduleStatusPaused
+	} else if s.status == models.ScheduleStatusPaused {
+		s.status = models.ScheduleStatusActive
+		s.next = s.nextAfter(now)
+	}
+	s.updatedAt = now
+
+	return nil
+}
+
+func (s *schedule) response() models.ScheduleResponse {
+	resp := models.ScheduleResponse{
+		ID:        s.id,
+		Status:    s.status,
+		Params:    s.params,
+		AttackIDs: append([]string{}, s.attackIDs...),
+		CreatedAt: s.createdAt.Format(time.RFC1123),
+		UpdatedAt: s.updatedAt.Format(time.RFC1123),
+	}
+
+	if s.status == models.ScheduleStatusActive {
+		resp.NextRunAt = s.next.Format(time.RFC1123)
+	}
+
+	return resp
+}
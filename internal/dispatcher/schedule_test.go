@@ -0,0 +1,85 @@
+package dispatcher
+
+import (
+	"testing"
+	"time"
+	"vegeta-server/models"
+)
+
+func Test_newSchedule(t *testing.T) {
+	now := time.Date(2019, 2, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		params   models.ScheduleParams
+		wantNext time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "OK - start_at",
+			params:   models.ScheduleParams{StartAt: "2019-02-01T12:00:00Z"},
+			wantNext: time.Date(2019, 2, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "OK - cron",
+			params:   models.ScheduleParams{Cron: "0 2 * * *"},
+			wantNext: time.Date(2019, 2, 2, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "OK - cron starting at start_at",
+			params:   models.ScheduleParams{StartAt: "2019-02-10T02:00:00Z", Cron: "0 2 * * *"},
+			wantNext: time.Date(2019, 2, 10, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "Error - no start_at or cron",
+			params:  models.ScheduleParams{},
+			wantErr: true,
+		},
+		{
+			name:    "Error - invalid start_at",
+			params:  models.ScheduleParams{StartAt: "tomorrow"},
+			wantErr: true,
+		},
+		{
+			name:    "Error - invalid cron",
+			params:  models.ScheduleParams{Cron: "* * *"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newSchedule(tt.params, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !s.next.Equal(tt.wantNext) {
+				t.Errorf("newSchedule() next = %v, want %v", s.next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func Test_schedule_fired(t *testing.T) {
+	now := time.Date(2019, 2, 1, 10, 30, 0, 0, time.UTC)
+
+	s, _ := newSchedule(models.ScheduleParams{Cron: "*/15 * * * *"}, now)
+	if s.due(now) || !s.due(s.next) {
+		t.Fatalf("schedule.due() wrong for next = %v", s.next)
+	}
+
+	s.fired("a", s.next)
+	if s.status != models.ScheduleStatusActive || !s.next.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("schedule.fired() status = %s, next = %v", s.status, s.next)
+	}
+
+	once, _ := newSchedule(models.ScheduleParams{StartAt: "2019-02-01T10:00:00Z"}, now)
+	once.fired("b", now)
+	if once.status != models.ScheduleStatusDone || once.due(now) {
+		t.Errorf("schedule.fired() status = %s, want done", once.status)
+	}
+	if err := once.pause(true, now); err == nil {
+		t.Error("schedule.pause() want error for done schedule")
+	}
+}
@@ -3,6 +3,7 @@ package dispatcher
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -11,14 +12,15 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"io"
-	"io/ioutil"
 	"vegeta-server/models"
+	"vegeta-server/pkg/vegeta"
 
 	"github.com/pkg/errors"
 )
 
-// AttackFunc provides type used by the attacker class
-type AttackFunc func(string, models.AttackParams, chan struct{}) (io.Reader, error)
+// AttackFunc provides type used by the attacker class. Snapshots of the rolling
+// attack metrics are sent on the progress channel while the attack runs.
+type AttackFunc func(string, models.AttackParams, chan struct{}, chan<- models.AttackMetrics) (io.Reader, error)
 
 // ITask defines an interface for attack tasks
 type ITask interface {
@@ -38,8 +40,20 @@ type ITaskGetter interface {
 	CreatedAt() time.Time
 	// UpdatedAt returns the updated at timestamp
 	UpdatedAt() time.Time
-	// Result returns the result as a byte array
+	// Result returns a reader of the result, read lazily from its spool file
 	Result() io.Reader
+	// ResultPath returns the path of the result spool file, once completed
+	ResultPath() string
+	// Metrics returns the latest snapshot of the rolling attack metrics
+	Metrics() models.AttackMetrics
+	// ScheduleID returns the ID of the schedule that created the task, if any
+	ScheduleID() string
+	// Verdict returns the verdict of the attack assertions, if any
+	Verdict() *models.Verdict
+	// Error returns why the attack failed, if it failed
+	Error() *models.AttackError
+	// Cancellation returns who canceled the attack, if it was canceled
+	Cancellation() *models.AttackCancellation
 }
 
 // ITaskActions defines an interface for the task action methods
@@ -48,12 +62,17 @@ type ITaskActions interface {
 	Run(AttackFunc) error
 	// Complete changes task status to completed
 	Complete(io.Reader) error
-	// Cancel changes task status to canceled
-	Cancel() error
-	// Fail changes task status to failed
-	Fail() error
+	// Cancel changes task status to canceled, recording who canceled it and why
+	Cancel(string, string) error
+	// Fail changes task status to failed, recording the error
+	Fail(error) error
 	// SendUpdate sends an update on the update chan to the caller
 	SendUpdate()
+	// Subscribe to the rolling metrics of a scheduled or running attack. The
+	// returned func must be called to unsubscribe.
+	Subscribe() (<-chan models.AttackMetrics, func(), error)
+	// Discard removes the result spool file of the task, if any
+	Discard() error
 }
 
 // UpdateMessage is a message type used to send updates to the dispatcher
@@ -68,15 +87,29 @@ type task struct {
 	id     string
 	params models.AttackParams
 	status models.AttackStatus
-	result *bytes.Buffer
+	// spool is the path of the result spool file, set once completed
+	spool string
 
 	createdAt time.Time
 	updatedAt time.Time
 
 	updateCh chan UpdateMessage
 	quit     chan struct{}
+
+	metrics      models.AttackMetrics
+	subscribers  map[chan models.AttackMetrics]struct{}
+	streamClosed bool
+
+	scheduleID   string
+	verdict      *models.Verdict
+	err          *models.AttackError
+	cancellation *models.AttackCancellation
 }
 
+// subscriberBufferSize is the number of metrics snapshots buffered for each
+// subscriber. Snapshots are dropped for subscribers that fall behind.
+const subscriberBufferSize = 10
+
 // NewTask returns a new instance of a task object
 func NewTask(updateCh chan UpdateMessage, params models.AttackParams) *task { //nolint: golint
 	id := uuid.NewV4().String()
@@ -85,13 +118,22 @@ func NewTask(updateCh chan UpdateMessage, params models.AttackParams) *task { //
 		id,
 		params,
 		models.AttackResponseStatusScheduled,
-		bytes.NewBuffer(make([]byte, 0)),
+		"",
 
 		time.Now(),
 		time.Now(),
 
 		updateCh,
 		make(chan struct{}),
+
+		models.AttackMetrics{ID: id},
+		make(map[chan models.AttackMetrics]struct{}),
+		false,
+
+		"",
+		nil,
+		nil,
+		nil,
 	}
 
 	t.log(nil).Debug("creating new task")
@@ -101,43 +143,70 @@ func NewTask(updateCh chan UpdateMessage, params models.AttackParams) *task { //
 
 // Run an attack task using the passed in attack function
 func (t *task) Run(fn AttackFunc) error {
-	status := t.Status()
-	id := t.ID()
-
-	if status != models.AttackResponseStatusScheduled {
-		return fmt.Errorf("cannot run task %s with status %s", id, status)
+	// The status is checked and set at once, as queued tasks may be canceled
+	// concurrently
+	t.mu.Lock()
+	if t.status != models.AttackResponseStatusScheduled {
+		defer t.mu.Unlock()
+		return fmt.Errorf("cannot run task %s with status %s", t.id, t.status)
 	}
+	t.status = models.AttackResponseStatusRunning
+	t.mu.Unlock()
 
 	t.log(nil).Debug("running")
 
 	go run(t, fn) //nolint: errcheck
 
-	t.mu.Lock()
-	t.status = models.AttackResponseStatusRunning
-	t.mu.Unlock()
-
 	t.SendUpdate()
 
 	return nil
 }
 
-// Complete marks a task as completed
+// Complete marks a task as completed. The task takes ownership of a result
+// spool, and spools other results first.
 func (t *task) Complete(result io.Reader) error {
 	status := t.Status()
 	id := t.ID()
 
+	// The task owns a result spool from here on, and discards it unless the
+	// task completes
+	spool, ok := result.(*vegeta.Spool)
 	if status != models.AttackResponseStatusRunning {
+		if ok {
+			_ = spool.Discard()
+		}
 		return fmt.Errorf("cannot mark completed for task %s with status %s", id, status)
 	}
 
-	buf, err := ioutil.ReadAll(result)
-	if err != nil {
-		return errors.Wrap(err, "failed to read result")
+	if !ok {
+		var err error
+		if spool, err = spoolResult(id, result); err != nil {
+			return err
+		}
+	}
+
+	// Evaluate the assertions against the metrics of the whole attack
+	var verdict *models.Verdict
+	params := t.Params()
+	if len(params.Assertions) > 0 {
+		m, err := vegeta.NewAttackMetricsFromReader(vegeta.SpoolReader(spool.Path()), id)
+		if err != nil {
+			_ = spool.Discard()
+			return errors.Wrap(err, "failed to evaluate assertions")
+		}
+		verdict = vegeta.EvaluateAssertions(params, m)
 	}
 
+	// The task may have been canceled meanwhile
 	t.mu.Lock()
+	if status = t.status; status != models.AttackResponseStatusRunning {
+		t.mu.Unlock()
+		_ = spool.Discard()
+		return fmt.Errorf("cannot mark completed for task %s with status %s", id, status)
+	}
 	t.status = models.AttackResponseStatusCompleted
-	t.result = bytes.NewBuffer(buf)
+	t.spool = spool.Path()
+	t.verdict = verdict
 	t.mu.Unlock()
 
 	t.SendUpdate()
@@ -148,38 +217,74 @@ func (t *task) Complete(result io.Reader) error {
 }
 
 // Cancel invokes the context cancel and marks a task as canceled
-func (t *task) Cancel() error {
-	status := t.Status()
-	id := t.ID()
+func (t *task) Cancel(by, reason string) error {
+	t.mu.Lock()
+	status := t.status
 
 	if status == models.AttackResponseStatusCompleted || status == models.AttackResponseStatusFailed || status == models.AttackResponseStatusCanceled { // nolint: lll
-		return fmt.Errorf("cannot cancel task %s with status %s", id, status)
+		defer t.mu.Unlock()
+		return fmt.Errorf("cannot cancel task %s with status %s", t.id, status)
 	}
 
-	t.mu.Lock()
-	t.quit <- struct{}{}
+	// Only running tasks have an attack to stop, queued tasks never run. The
+	// quit chan is closed rather than sent on, as the attack may have
+	// returned already while its result is completed.
+	if status == models.AttackResponseStatusRunning {
+		close(t.quit)
+	}
 	t.status = models.AttackResponseStatusCanceled
+	t.cancellation = &models.AttackCancellation{
+		By:        by,
+		Reason:    reason,
+		Timestamp: time.Now().Format(time.RFC1123),
+	}
 	t.mu.Unlock()
 
 	t.SendUpdate()
 
-	t.log(nil).Debug("canceled")
+	t.log(log.Fields{"By": by, "Reason": reason}).Debug("canceled")
 
 	return nil
 }
 
-// Fail marks a task as failed
-func (t *task) Fail() error {
+// Fail marks a task as failed, unless it has ended already
+func (t *task) Fail(err error) error {
 	t.mu.Lock()
+	status := t.status
+
+	if status == models.AttackResponseStatusCompleted || status == models.AttackResponseStatusFailed || status == models.AttackResponseStatusCanceled { // nolint: lll
+		defer t.mu.Unlock()
+		return fmt.Errorf("cannot mark failed for task %s with status %s", t.id, status)
+	}
+
 	t.status = models.AttackResponseStatusFailed
+	t.err = &models.AttackError{
+		Category:  vegeta.ErrorCategory(err),
+		Message:   err.Error(),
+		Timestamp: time.Now().Format(time.RFC1123),
+	}
 	t.mu.Unlock()
 
 	t.SendUpdate()
 
-	t.log(nil).Error("failed")
+	t.log(nil).WithError(err).Error("failed")
 	return nil
 }
 
+// Discard removes the result spool file of the task, if any
+func (t *task) Discard() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.spool == "" {
+		return nil
+	}
+
+	err := os.Remove(t.spool)
+	t.spool = ""
+	return err
+}
+
 // SendUpdate to send a status update on the update channel
 func (t *task) SendUpdate() {
 	t.mu.Lock()
@@ -192,6 +297,56 @@ func (t *task) SendUpdate() {
 	}
 }
 
+// Subscribe to the rolling metrics of a scheduled or running attack
+func (t *task) Subscribe() (<-chan models.AttackMetrics, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.streamClosed || (t.status != models.AttackResponseStatusScheduled && t.status != models.AttackResponseStatusRunning) { // nolint: lll
+		return nil, nil, fmt.Errorf("cannot stream task %s with status %s", t.id, t.status)
+	}
+
+	ch := make(chan models.AttackMetrics, subscriberBufferSize)
+	t.subscribers[ch] = struct{}{}
+
+	return ch, func() { t.unsubscribe(ch) }, nil
+}
+
+func (t *task) unsubscribe(ch chan models.AttackMetrics) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.subscribers[ch]; ok {
+		delete(t.subscribers, ch)
+		close(ch)
+	}
+}
+
+// publish forwards the metrics received on the progress channel to all
+// subscribers, and closes their channels once the progress channel is closed.
+func (t *task) publish(progress <-chan models.AttackMetrics) {
+	for m := range progress {
+		t.mu.Lock()
+		t.metrics = m
+		for ch := range t.subscribers {
+			select {
+			case ch <- m:
+			default:
+			}
+		}
+		t.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.streamClosed = true
+	for ch := range t.subscribers {
+		delete(t.subscribers, ch)
+		close(ch)
+	}
+}
+
 // ID returns the task identifier
 func (t *task) ID() string {
 	t.mu.RLock()
@@ -228,17 +383,67 @@ func (t *task) UpdatedAt() time.Time {
 	return t.updatedAt
 }
 
-// Result returns the result as a io.Reader
+// Result returns the result as a io.Reader, read lazily from the spool file
 func (t *task) Result() io.Reader {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	return t.result
+
+	if t.spool == "" {
+		return bytes.NewReader(nil)
+	}
+	return vegeta.SpoolReader(t.spool)
+}
+
+// ResultPath returns the path of the result spool file, once completed
+func (t *task) ResultPath() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.spool
+}
+
+// Metrics returns the latest snapshot of the rolling attack metrics
+func (t *task) Metrics() models.AttackMetrics {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.metrics
+}
+
+// ScheduleID returns the ID of the schedule that created the task, if any
+func (t *task) ScheduleID() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.scheduleID
+}
+
+// Verdict returns the verdict of the attack assertions, if any
+func (t *task) Verdict() *models.Verdict {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.verdict
+}
+
+// Error returns why the attack failed, if it failed
+func (t *task) Error() *models.AttackError {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.err
+}
+
+// Cancellation returns who canceled the attack, if it was canceled
+func (t *task) Cancellation() *models.AttackCancellation {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.cancellation
 }
 
 func run(t *task, fn AttackFunc) {
-	buf, err := fn(t.id, t.params, t.quit)
+	progress := make(chan models.AttackMetrics)
+	go t.publish(progress)
+
+	buf, err := fn(t.id, t.params, t.quit, progress)
+	close(progress)
 	if err != nil {
-		_ = t.Fail()
+		_ = t.Fail(err)
 	}
 
 	// Attack was canceled
@@ -246,11 +451,11 @@ func run(t *task, fn AttackFunc) {
 		return
 	}
 
-	// Mark attack as completed
+	// Mark attack as completed. Attacks canceled meanwhile stay canceled, as
+	// ended tasks cannot fail.
 	err = t.Complete(buf)
 	if err != nil {
-		log.WithError(err).Error("Failed to Complete")
-		_ = t.Fail()
+		_ = t.Fail(err)
 	}
 }
 
@@ -268,21 +473,46 @@ func (t *task) log(fields map[string]interface{}) *log.Entry {
 	return l
 }
 
+// spoolResult writes a result to a new spool
+func spoolResult(id string, result io.Reader) (*vegeta.Spool, error) {
+	spool, err := vegeta.NewSpool(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to spool result")
+	}
+
+	if _, err = io.Copy(spool, result); err == nil {
+		err = spool.Close()
+	}
+	if err != nil {
+		_ = spool.Discard()
+		return nil, errors.Wrap(err, "failed to spool result")
+	}
+
+	return spool, nil
+}
+
+// attackDetailFromTask returns the details of a task. Completed tasks carry the
+// path and size of their result spool file, which is read lazily by reports.
 func attackDetailFromTask(t ITaskGetter) models.AttackDetails {
 	details := models.AttackDetails{
 		AttackInfo: models.AttackInfo{
-			ID:        t.ID(),
-			Status:    t.Status(),
-			Params:    t.Params(),
-			CreatedAt: t.CreatedAt().Format(time.RFC1123),
-			UpdatedAt: t.UpdatedAt().Format(time.RFC1123),
+			ID:           t.ID(),
+			Status:       t.Status(),
+			Params:       t.Params(),
+			ScheduleID:   t.ScheduleID(),
+			Verdict:      t.Verdict(),
+			Error:        t.Error(),
+			Cancellation: t.Cancellation(),
+			CreatedAt:    t.CreatedAt().Format(time.RFC1123),
+			UpdatedAt:    t.UpdatedAt().Format(time.RFC1123),
 		},
 	}
 
-	if t.Status() == models.AttackResponseStatusCompleted {
-		result := t.Result()
-		buf, _ := ioutil.ReadAll(result)
-		details.Result = buf
+	if path := t.ResultPath(); path != "" && t.Status() == models.AttackResponseStatusCompleted {
+		details.ResultPath = path
+		if info, err := os.Stat(path); err == nil {
+			details.ResultSize = info.Size()
+		}
 	}
 
 	return details
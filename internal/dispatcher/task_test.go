@@ -0,0 +1,205 @@
+package dispatcher
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+	"vegeta-server/models"
+	spool "vegeta-server/pkg/vegeta"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func TestMain(m *testing.M) {
+	// Results of the attacks run by the tests are spooled to a directory
+	// removed afterwards
+	dir, err := ioutil.TempDir("", "vegeta-server")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	spool.SpoolDir = dir
+
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func Test_task_Complete_Assertions(t *testing.T) {
+	updateCh := make(chan UpdateMessage, 10)
+
+	buf := new(bytes.Buffer)
+	enc := vegeta.NewEncoder(buf)
+	for i := 0; i < 10; i++ {
+		_ = enc.Encode(&vegeta.Result{
+			Code:      200,
+			Timestamp: time.Now(),
+			Latency:   10 * time.Millisecond,
+		})
+	}
+
+	task := NewTask(updateCh, models.AttackParams{
+		Rate:       10,
+		Assertions: []string{"p99 < 100ms", "requests >= 20"},
+	})
+	task.status = models.AttackResponseStatusRunning
+
+	if err := task.Complete(buf); err != nil {
+		t.Fatal(err)
+	}
+	defer task.Discard() // nolint: errcheck
+
+	verdict := attackDetailFromTask(task).Verdict
+	if verdict == nil || verdict.Pass || len(verdict.Assertions) != 2 {
+		t.Fatalf("task verdict = %v, want a failed verdict", verdict)
+	}
+	if !verdict.Assertions[0].Pass || verdict.Assertions[1].Pass {
+		t.Errorf("task verdict assertions = %v", verdict.Assertions)
+	}
+}
+
+func Test_task_Complete_Spool(t *testing.T) {
+	updateCh := make(chan UpdateMessage, 10)
+	task := NewTask(updateCh, models.AttackParams{})
+	task.status = models.AttackResponseStatusRunning
+
+	if err := task.Complete(bytes.NewBufferString("result")); err != nil {
+		t.Fatal(err)
+	}
+
+	path := task.spool
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("task spool file error = %v", err)
+	}
+
+	// The result is read from the spool file as many times as needed
+	for i := 0; i < 2; i++ {
+		if got, _ := ioutil.ReadAll(task.Result()); string(got) != "result" {
+			t.Errorf("task result = %s, want result", got)
+		}
+	}
+	// The details only carry the path and size of the spool file
+	if got := attackDetailFromTask(task); got.Result != nil || got.ResultPath != path || got.ResultLen() != 6 {
+		t.Errorf("task details result = %s, %s, %d, want %s of size 6", got.Result, got.ResultPath, got.ResultLen(), path)
+	}
+
+	if err := task.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("task spool file %s not removed", path)
+	}
+	if got, _ := ioutil.ReadAll(task.Result()); len(got) != 0 {
+		t.Errorf("discarded task result = %s, want none", got)
+	}
+}
+
+func Test_task_Complete_Canceled(t *testing.T) {
+	updateCh := make(chan UpdateMessage, 10)
+	task := NewTask(updateCh, models.AttackParams{})
+	task.status = models.AttackResponseStatusRunning
+
+	result, err := spool.NewSpool(task.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The attack is canceled after it returned, before its result completes
+	fn := func(string, models.AttackParams, chan struct{}, chan<- models.AttackMetrics) (io.Reader, error) {
+		if err := task.Cancel("ci", "superseded"); err != nil {
+			t.Fatal(err)
+		}
+		return result, nil
+	}
+	run(task, fn)
+
+	got := attackDetailFromTask(task)
+	if got.Status != models.AttackResponseStatusCanceled || got.Error != nil {
+		t.Errorf("task = %v, %v, want a canceled task without an error", got.AttackInfo, got.Error)
+	}
+	if _, err := os.Stat(result.Path()); !os.IsNotExist(err) {
+		t.Errorf("task spool file %s not removed", result.Path())
+	}
+	if len(updateCh) != 1 {
+		t.Errorf("task sent %d updates, want 1", len(updateCh))
+	}
+}
+
+func Test_task_Fail(t *testing.T) {
+	updateCh := make(chan UpdateMessage, 10)
+	task := NewTask(updateCh, models.AttackParams{})
+
+	if err := task.Fail(fmt.Errorf("oops")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := attackDetailFromTask(task)
+	if got.Status != models.AttackResponseStatusFailed || got.Error == nil {
+		t.Fatalf("task = %v, want a failed task with an error", got.AttackInfo)
+	}
+	if got.Error.Category != models.AttackErrorCategoryInternal || got.Error.Message != "oops" || got.Error.Timestamp == "" {
+		t.Errorf("task error = %v", got.Error)
+	}
+}
+
+func Test_task_Fail_Ended(t *testing.T) {
+	updateCh := make(chan UpdateMessage, 10)
+	task := NewTask(updateCh, models.AttackParams{})
+
+	if err := task.Cancel("ci", "superseded"); err != nil {
+		t.Fatal(err)
+	}
+	if err := task.Fail(fmt.Errorf("oops")); err == nil {
+		t.Error("task.Fail() error = nil, want an error for a canceled task")
+	}
+	if got := task.Status(); got != models.AttackResponseStatusCanceled {
+		t.Errorf("task status = %s, want %s", got, models.AttackResponseStatusCanceled)
+	}
+}
+
+func Test_task_Cancel(t *testing.T) {
+	updateCh := make(chan UpdateMessage, 10)
+	task := NewTask(updateCh, models.AttackParams{})
+
+	if err := task.Cancel("ci", "superseded"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := attackDetailFromTask(task)
+	if got.Status != models.AttackResponseStatusCanceled || got.Cancellation == nil {
+		t.Fatalf("task = %v, want a canceled task with a cancellation", got.AttackInfo)
+	}
+	if got.Cancellation.By != "ci" || got.Cancellation.Reason != "superseded" || got.Cancellation.Timestamp == "" {
+		t.Errorf("task cancellation = %v", got.Cancellation)
+	}
+}
+
+func Test_task_Cancel_Returned(t *testing.T) {
+	updateCh := make(chan UpdateMessage, 10)
+	task := NewTask(updateCh, models.AttackParams{})
+
+	// The attack has returned, and nothing reads the quit chan anymore
+	task.status = models.AttackResponseStatusRunning
+
+	done := make(chan error)
+	go func() {
+		done <- task.Cancel("ci", "superseded")
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out canceling task")
+	}
+
+	if _, ok := <-task.quit; ok {
+		t.Error("task quit chan is not closed")
+	}
+}
@@ -1,16 +1,52 @@
 package endpoints
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strconv"
+	"vegeta-server/internal/dispatcher"
 	"vegeta-server/models"
+	"vegeta-server/pkg/vegeta"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pkg/errors"
 )
 
 // PostAttackEndpoint implements a handler for the POST /api/v1/attack endpoint
 func (e *Endpoints) PostAttackEndpoint(c *gin.Context) {
 	var attackParams models.AttackParams
-	if err := c.ShouldBindJSON(&attackParams); err != nil {
+	var err error
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		err = bindMultipartAttackParams(c, &attackParams)
+	} else {
+		err = c.ShouldBindJSON(&attackParams)
+	}
+	if err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	// Check the targets, labels, ramp and assertions up front, rather than failing the attack later
+	if err = vegeta.ValidateTargets(attackParams); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	if err = models.ValidateLabels(attackParams.Labels); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	if err = vegeta.ValidateRamp(attackParams); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	if err = vegeta.ValidateAssertions(attackParams); err != nil {
 		ginErrBadRequest(c, err)
 		return
 	}
@@ -37,18 +73,127 @@ func (e *Endpoints) GetAttackByIDEndpoint(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
-// GetAttackEndpoint implements a handler for the GET /api/v1/attack endpoint
+// GetAttackEndpoint implements a handler for the GET /api/v1/attack endpoint.
+// The total number of attacks matching the filters is set in the
+// X-Total-Count header, and the cursor of the next page in X-Next-Cursor.
 func (e *Endpoints) GetAttackEndpoint(c *gin.Context) {
+	filterMap := attackFilters(c)
+	if err := models.ValidateFilters(filterMap); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	listParams, err := attackListParams(c)
+	if err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	resp, err := e.dispatcher.List(filterMap, listParams)
+	if err != nil {
+		ginErrInternalServerError(c, err)
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(resp.Total))
+	if resp.NextCursor != "" {
+		c.Header("X-Next-Cursor", resp.NextCursor)
+	}
+	c.JSON(http.StatusOK, resp.Attacks)
+}
+
+// DeleteAttackByIDEndpoint implements a handler for the DELETE /api/v1/attack/<attackID> endpoint.
+// Scheduled and running attacks are refused, unless the cancel query param is set.
+func (e *Endpoints) DeleteAttackByIDEndpoint(c *gin.Context) {
+	e.deleteAttack(c, c.Param("attackID"))
+}
+
+// DeleteAttackEndpoint implements a handler for the DELETE /api/v1/attack endpoint.
+// The attacks matching the same filters as GET /api/v1/attack are deleted. At
+// least one filter, or the all query param, must be set.
+func (e *Endpoints) DeleteAttackEndpoint(c *gin.Context) {
+	filterMap := attackFilters(c)
+	if err := models.ValidateFilters(filterMap); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	filtered := false
+	for _, v := range filterMap {
+		filtered = filtered || v != ""
+	}
+	if !filtered && c.Query("all") != "true" {
+		ginErrBadRequest(c, errors.New("set a filter, or all=true to delete all attacks"))
+		return
+	}
+
+	resp := e.dispatcher.DeleteAll(filterMap, c.Query("cancel") == "true")
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// deleteAttack deletes an attack along with its report
+func (e *Endpoints) deleteAttack(c *gin.Context, id string) {
+	attack, err := e.dispatcher.Get(id)
+	if err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+
+	cancel := c.Query("cancel") == "true"
+	ended := attack.Status != models.AttackResponseStatusScheduled && attack.Status != models.AttackResponseStatusRunning
+	if !cancel && !ended {
+		ginErrConflict(c, fmt.Errorf("cannot delete attack %s with status %s, cancel it first", id, attack.Status))
+		return
+	}
+
+	// The status may have changed since it was checked above
+	err = e.dispatcher.Delete(id, cancel)
+	if errors.Cause(err) == dispatcher.ErrActive {
+		ginErrConflict(c, err)
+		return
+	}
+	if err != nil {
+		ginErrInternalServerError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// attackFilters reads the attack filters from the query params
+func attackFilters(c *gin.Context) models.FilterParams {
 	filterMap := make(models.FilterParams)
 	filterMap["status"] = c.DefaultQuery("status", "")
 	filterMap["created_before"] = c.DefaultQuery("created_before", "")
 	filterMap["created_after"] = c.DefaultQuery("created_after", "")
-	resp := e.dispatcher.List(
-		//models.StatusFilter(status),
-		filterMap,
-	)
+	filterMap["updated_before"] = c.DefaultQuery("updated_before", "")
+	filterMap["updated_after"] = c.DefaultQuery("updated_after", "")
+	filterMap["url_prefix"] = c.DefaultQuery("url_prefix", "")
+	filterMap["url_regex"] = c.DefaultQuery("url_regex", "")
+	filterMap["method"] = c.DefaultQuery("method", "")
+	filterMap["rate_min"] = c.DefaultQuery("rate_min", "")
+	filterMap["rate_max"] = c.DefaultQuery("rate_max", "")
+	filterMap["labels"] = c.DefaultQuery("labels", "")
+	return filterMap
+}
 
-	c.JSON(http.StatusOK, resp)
+// attackListParams reads the attack list sorting and pagination from the query params
+func attackListParams(c *gin.Context) (models.ListParams, error) {
+	params := models.ListParams{
+		Sort:   models.ListSortField(c.DefaultQuery("sort", "")),
+		Order:  models.ListOrder(c.DefaultQuery("order", "")),
+		Cursor: c.DefaultQuery("cursor", ""),
+	}
+
+	if limit := c.DefaultQuery("limit", ""); limit != "" {
+		var err error
+		if params.Limit, err = strconv.Atoi(limit); err != nil {
+			return params, fmt.Errorf("invalid limit %q, want an integer", limit)
+		}
+	}
+
+	return params, params.Validate()
 }
 
 // PostAttackByIDCancelEndpoint implements a handler for the POST /api/v1/attack/<attackID>/cancel endpoint
@@ -66,7 +211,7 @@ func (e *Endpoints) PostAttackByIDCancelEndpoint(c *gin.Context) {
 		return
 	}
 
-	err = e.dispatcher.Cancel(id, attackCancelParams.Cancel)
+	err = e.dispatcher.Cancel(id, attackCancelParams)
 	if err != nil {
 		ginErrInternalServerError(c, err)
 		return
@@ -74,3 +219,87 @@ func (e *Endpoints) PostAttackByIDCancelEndpoint(c *gin.Context) {
 
 	c.Status(http.StatusOK)
 }
+
+// GetAttackByIDStreamEndpoint implements a handler for the GET /api/v1/attack/<attackID>/stream endpoint.
+// The rolling metrics of a scheduled or running attack are sent as Server-Sent Events,
+// until the attack ends or the client goes away.
+func (e *Endpoints) GetAttackByIDStreamEndpoint(c *gin.Context) {
+	id := c.Param("attackID")
+	ch, stop, err := e.dispatcher.Stream(id)
+	if errors.Cause(err) == dispatcher.ErrEnded {
+		ginErrConflict(c, err)
+		return
+	}
+	if err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+	defer stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	for {
+		select {
+		case m, ok := <-ch:
+			if !ok {
+				return
+			}
+			c.SSEvent("metrics", m)
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// GetAttackByIDWebhooksEndpoint implements a handler for the GET /api/v1/attack/<attackID>/webhooks endpoint
+func (e *Endpoints) GetAttackByIDWebhooksEndpoint(c *gin.Context) {
+	id := c.Param("attackID")
+	resp, err := e.dispatcher.Deliveries(id)
+	if err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// bindMultipartAttackParams binds a multipart attack submission. The attack
+// params are read as JSON from the "params" form field, and an optional target
+// file is read from the "targets" form file, in the format set by the "format"
+// form field.
+func bindMultipartAttackParams(c *gin.Context, params *models.AttackParams) error {
+	if err := json.Unmarshal([]byte(c.PostForm("params")), params); err != nil {
+		return errors.Wrap(err, "failed to unmarshal params form field")
+	}
+
+	fh, err := c.FormFile("targets")
+	if err == http.ErrMissingFile {
+		return binding.Validator.ValidateStruct(params)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get targets form file")
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return errors.Wrap(err, "failed to open targets form file")
+	}
+	defer f.Close() // nolint: errcheck
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return errors.Wrap(err, "failed to read targets form file")
+	}
+
+	params.TargetFile = &models.TargetFile{
+		Format: c.DefaultPostForm("format", "http"),
+		Data:   base64.StdEncoding.EncodeToString(data),
+	}
+
+	return binding.Validator.ValidateStruct(params)
+}
@@ -1,8 +1,11 @@
 package endpoints
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -11,13 +14,14 @@ import (
 	dmocks "vegeta-server/internal/dispatcher/mocks"
 	"vegeta-server/models"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
 
 	assert "gopkg.in/go-playground/assert.v1"
 )
 
 func setupTestDispatcherRouter(d dispatcher.IDispatcher, req *http.Request) *httptest.ResponseRecorder {
-	router := SetupRouter(d, nil)
+	router := SetupRouter(d, nil, nil)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -128,6 +132,206 @@ func TestEndpoints_PostAttackEndpoint(t *testing.T) {
 				http.StatusOK,
 			},
 		},
+		{
+			name: "OK - Multiple targets",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					attackParams := models.AttackParams{
+						Rate: 1,
+						Targets: []models.Target{
+							{
+								Method: "GET",
+								URL:    "localhost:80/api/v1/",
+							},
+							{
+								Method: "POST",
+								URL:    "localhost:80/api/v1/attack",
+							},
+						},
+						Duration: "1s",
+					}
+					d := new(dmocks.IDispatcher)
+
+					d.
+						On("Dispatch", attackParams).
+						Return(nil, nil)
+					bAttackParamsBody, _ := json.Marshal(attackParams)
+					attackParamsBody := string(bAttackParamsBody)
+
+					req, _ := http.NewRequest("POST", "/api/v1/attack", strings.NewReader(attackParamsBody))
+
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+		{
+			name: "Bad Request - Invalid ramp",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					attackParams := models.AttackParams{
+						Rate: 10,
+						Ramp: &models.Ramp{
+							Type:      models.RampTypeSine,
+							Amplitude: 20,
+							Period:    "1m",
+						},
+						Target: models.Target{
+							Method: "GET",
+							URL:    "localhost:80/api/v1/",
+						},
+						Duration: "1s",
+					}
+					bAttackParamsBody, _ := json.Marshal(attackParams)
+					attackParamsBody := string(bAttackParamsBody)
+
+					req, _ := http.NewRequest("POST", "/api/v1/attack", strings.NewReader(attackParamsBody))
+
+					return new(dmocks.IDispatcher), req
+				},
+				http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request - Invalid assertion",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					attackParams := models.AttackParams{
+						Rate:       10,
+						Assertions: []string{"p99 < fast"},
+						Target: models.Target{
+							Method: "GET",
+							URL:    "localhost:80/api/v1/",
+						},
+						Duration: "1s",
+					}
+					bAttackParamsBody, _ := json.Marshal(attackParams)
+					attackParamsBody := string(bAttackParamsBody)
+
+					req, _ := http.NewRequest("POST", "/api/v1/attack", strings.NewReader(attackParamsBody))
+
+					return new(dmocks.IDispatcher), req
+				},
+				http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request - Invalid labels",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					attackParams := models.AttackParams{
+						Rate:   10,
+						Labels: map[string]string{"env": "!prod"},
+						Target: models.Target{
+							Method: "GET",
+							URL:    "localhost:80/api/v1/",
+						},
+						Duration: "1s",
+					}
+					bAttackParamsBody, _ := json.Marshal(attackParams)
+					attackParamsBody := string(bAttackParamsBody)
+
+					req, _ := http.NewRequest("POST", "/api/v1/attack", strings.NewReader(attackParamsBody))
+
+					return new(dmocks.IDispatcher), req
+				},
+				http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request - No target",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					attackParams := models.AttackParams{
+						Rate:     1,
+						Duration: "1s",
+					}
+					bAttackParamsBody, _ := json.Marshal(attackParams)
+					attackParamsBody := string(bAttackParamsBody)
+
+					req, _ := http.NewRequest("POST", "/api/v1/attack", strings.NewReader(attackParamsBody))
+
+					return new(dmocks.IDispatcher), req
+				},
+				http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request - Invalid target file",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					attackParams := models.AttackParams{
+						Rate: 1,
+						TargetFile: &models.TargetFile{
+							Format: "http",
+							Data:   base64.StdEncoding.EncodeToString([]byte("localhost:80/api/v1/")),
+						},
+						Duration: "1s",
+					}
+					bAttackParamsBody, _ := json.Marshal(attackParams)
+					attackParamsBody := string(bAttackParamsBody)
+
+					req, _ := http.NewRequest("POST", "/api/v1/attack", strings.NewReader(attackParamsBody))
+
+					return new(dmocks.IDispatcher), req
+				},
+				http.StatusBadRequest,
+			},
+		},
+		{
+			name: "OK - Multipart target file",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					targets := "GET http://localhost:80/api/v1/\n\nPOST http://localhost:80/api/v1/attack\n"
+					attackParams := models.AttackParams{
+						Rate:     1,
+						Duration: "1s",
+					}
+					bAttackParamsBody, _ := json.Marshal(attackParams)
+
+					body := new(bytes.Buffer)
+					mw := multipart.NewWriter(body)
+					_ = mw.WriteField("params", string(bAttackParamsBody))
+					_ = mw.WriteField("format", "http")
+					fw, _ := mw.CreateFormFile("targets", "targets.txt")
+					_, _ = fw.Write([]byte(targets))
+					_ = mw.Close()
+
+					attackParams.TargetFile = &models.TargetFile{
+						Format: "http",
+						Data:   base64.StdEncoding.EncodeToString([]byte(targets)),
+					}
+					d := new(dmocks.IDispatcher)
+
+					d.
+						On("Dispatch", attackParams).
+						Return(nil, nil)
+
+					req, _ := http.NewRequest("POST", "/api/v1/attack", body)
+					req.Header.Set("Content-Type", mw.FormDataContentType())
+
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+		{
+			name: "Bad Request - Multipart missing params",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					body := new(bytes.Buffer)
+					mw := multipart.NewWriter(body)
+					_ = mw.WriteField("format", "http")
+					_ = mw.Close()
+
+					req, _ := http.NewRequest("POST", "/api/v1/attack", body)
+					req.Header.Set("Content-Type", mw.FormDataContentType())
+
+					return new(dmocks.IDispatcher), req
+				},
+				http.StatusBadRequest,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -202,8 +406,9 @@ func TestEndpoints_GetAttackByIDEndpoint(t *testing.T) {
 
 func TestEndpoints_GetAttackEndpoint(t *testing.T) {
 	type params struct {
-		setup    setupDispatcherFunc
-		wantCode int
+		setup       setupDispatcherFunc
+		wantCode    int
+		wantHeaders map[string]string
 	}
 	tests := []struct {
 		name   string
@@ -219,14 +424,300 @@ func TestEndpoints_GetAttackEndpoint(t *testing.T) {
 							"status":         "",
 							"created_before": "",
 							"created_after":  "",
-						}).
-						Return([]*models.AttackResponse{})
+							"updated_before": "",
+							"updated_after":  "",
+							"url_prefix":     "",
+							"url_regex":      "",
+							"method":         "",
+							"rate_min":       "",
+							"rate_max":       "",
+							"labels":         "",
+						}, models.ListParams{Sort: models.ListSortCreatedAt, Order: models.ListOrderDesc}).
+						Return(models.AttackList{Attacks: []*models.AttackResponse{}}, nil)
 
 					// Setup router
 					req, _ := http.NewRequest("GET", "/api/v1/attack", nil)
 					return d, req
 				},
 				wantCode: http.StatusOK,
+				wantHeaders: map[string]string{
+					"X-Total-Count": "0",
+					"X-Next-Cursor": "",
+				},
+			},
+		},
+		{
+			name: "OK Page",
+			params: params{
+				setup: func() (iDispatcher dispatcher.IDispatcher, request *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("List", mock.Anything, models.ListParams{
+							Sort:   models.ListSortUpdatedAt,
+							Order:  models.ListOrderAsc,
+							Limit:  1,
+							Cursor: "MTU1MDAwMDAwMHwxMjM",
+						}).
+						Return(models.AttackList{
+							Attacks:    []*models.AttackResponse{{ID: "456"}},
+							Total:      3,
+							NextCursor: "MTU1MDAwMDAwMHw0NTY",
+						}, nil)
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/attack?sort=updated_at&order=asc&limit=1&cursor=MTU1MDAwMDAwMHwxMjM", nil)
+					return d, req
+				},
+				wantCode: http.StatusOK,
+				wantHeaders: map[string]string{
+					"X-Total-Count": "3",
+					"X-Next-Cursor": "MTU1MDAwMDAwMHw0NTY",
+				},
+			},
+		},
+		{
+			name: "Bad Request Filter",
+			params: params{
+				setup: func() (iDispatcher dispatcher.IDispatcher, request *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/attack?url_regex=(", nil)
+					return &dmocks.IDispatcher{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request Labels",
+			params: params{
+				setup: func() (iDispatcher dispatcher.IDispatcher, request *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/attack?labels=team%3Dpayments%2C", nil)
+					return &dmocks.IDispatcher{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request Limit",
+			params: params{
+				setup: func() (iDispatcher dispatcher.IDispatcher, request *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/attack?limit=ten", nil)
+					return &dmocks.IDispatcher{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request Sort",
+			params: params{
+				setup: func() (iDispatcher dispatcher.IDispatcher, request *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/attack?sort=rate", nil)
+					return &dmocks.IDispatcher{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request Cursor",
+			params: params{
+				setup: func() (iDispatcher dispatcher.IDispatcher, request *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/attack?cursor=!!!", nil)
+					return &dmocks.IDispatcher{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestDispatcherRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+			for k, v := range tt.params.wantHeaders {
+				assert.Equal(t, v, w.Header().Get(k))
+			}
+		})
+	}
+}
+
+func TestEndpoints_DeleteAttackByIDEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupDispatcherFunc
+		wantCode int
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Not Found",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("Get", "123").
+						Return(nil, fmt.Errorf("not found"))
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack/123", nil)
+					return d, req
+				},
+				http.StatusNotFound,
+			},
+		},
+		{
+			name: "Conflict",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("Get", "123").
+						Return(&models.AttackResponse{ID: "123", Status: models.AttackResponseStatusRunning}, nil)
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack/123", nil)
+					return d, req
+				},
+				http.StatusConflict,
+			},
+		},
+		{
+			name: "Conflict - Active on delete",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("Get", "123").
+						Return(&models.AttackResponse{ID: "123", Status: models.AttackResponseStatusCompleted}, nil)
+					d.
+						On("Delete", "123", false).
+						Return(errors.Wrap(dispatcher.ErrActive, "cannot delete attack 123 with status running"))
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack/123", nil)
+					return d, req
+				},
+				http.StatusConflict,
+			},
+		},
+		{
+			name: "Internal Server Error",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("Get", "123").
+						Return(&models.AttackResponse{ID: "123", Status: models.AttackResponseStatusCompleted}, nil)
+					d.
+						On("Delete", "123", false).
+						Return(fmt.Errorf("internal server error"))
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack/123", nil)
+					return d, req
+				},
+				http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "OK",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("Get", "123").
+						Return(&models.AttackResponse{ID: "123", Status: models.AttackResponseStatusCompleted}, nil)
+					d.
+						On("Delete", "123", false).
+						Return(nil)
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack/123", nil)
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+		{
+			name: "OK Cancel",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("Get", "123").
+						Return(&models.AttackResponse{ID: "123", Status: models.AttackResponseStatusRunning}, nil)
+					d.
+						On("Delete", "123", true).
+						Return(nil)
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack/123?cancel=true", nil)
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestDispatcherRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+		})
+	}
+}
+
+func TestEndpoints_DeleteAttackEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupDispatcherFunc
+		wantCode int
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Bad Request No Filter",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack", nil)
+					return &dmocks.IDispatcher{}, req
+				},
+				http.StatusBadRequest,
+			},
+		},
+		{
+			name: "OK Filter",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("DeleteAll", models.FilterParams{
+							"status":         "completed",
+							"created_before": "",
+							"created_after":  "",
+							"updated_before": "",
+							"updated_after":  "",
+							"url_prefix":     "",
+							"url_regex":      "",
+							"method":         "",
+							"rate_min":       "",
+							"rate_max":       "",
+							"labels":         "",
+						}, false).
+						Return(models.AttackDeleteResponse{Deleted: []string{"123"}, Skipped: []string{}})
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack?status=completed", nil)
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+		{
+			name: "OK All",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("DeleteAll", mock.Anything, true).
+						Return(models.AttackDeleteResponse{Deleted: []string{"123"}, Skipped: []string{}})
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/attack?all=true&cancel=true", nil)
+					return d, req
+				},
+				http.StatusOK,
 			},
 		},
 	}
@@ -293,7 +784,7 @@ func TestEndpoints_PostAttackByIDCancelEndpoint(t *testing.T) {
 
 					// Return error on Cancel
 					d.
-						On("Cancel", "123", true).
+						On("Cancel", "123", models.AttackCancel{Cancel: true}).
 						Return(fmt.Errorf("internal server error"))
 
 					bAttackCancelBody, _ := json.Marshal(&models.AttackCancel{
@@ -319,7 +810,7 @@ func TestEndpoints_PostAttackByIDCancelEndpoint(t *testing.T) {
 
 					// Return error on Cancel
 					d.
-						On("Cancel", "123", true).
+						On("Cancel", "123", models.AttackCancel{Cancel: true}).
 						Return(nil)
 
 					bAttackCancelBody, _ := json.Marshal(&models.AttackCancel{
@@ -342,3 +833,147 @@ func TestEndpoints_PostAttackByIDCancelEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestEndpoints_GetAttackByIDWebhooksEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupDispatcherFunc
+		wantCode int
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Not Found",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+
+					// Prepare mock
+					d.
+						On("Deliveries", "123").
+						Return(nil, fmt.Errorf("not found"))
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/attack/123/webhooks", nil)
+					return d, req
+				},
+				http.StatusNotFound,
+			},
+		},
+		{
+			name: "OK",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+
+					// Prepare mock
+					d.
+						On("Deliveries", "123").
+						Return([]models.WebhookDelivery{
+							{
+								EventID:    "456",
+								Status:     models.AttackResponseStatusCompleted,
+								URL:        "http://localhost/hook",
+								Attempt:    1,
+								Success:    true,
+								StatusCode: http.StatusOK,
+							},
+						}, nil)
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/attack/123/webhooks", nil)
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestDispatcherRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+		})
+	}
+}
+
+func TestEndpoints_GetAttackByIDStreamEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupDispatcherFunc
+		wantCode int
+		wantBody string
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Not Found",
+			params: params{
+				setup: func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("Stream", "123").
+						Return(nil, nil, fmt.Errorf("not found"))
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/attack/123/stream", nil)
+					return d, req
+				},
+				wantCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "Ended",
+			params: params{
+				setup: func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("Stream", "123").
+						Return(nil, nil, errors.Wrap(dispatcher.ErrEnded, "cannot stream task 123 with status completed"))
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/attack/123/stream", nil)
+					return d, req
+				},
+				wantCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "OK",
+			params: params{
+				setup: func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+
+					ch := make(chan models.AttackMetrics, 1)
+					ch <- models.AttackMetrics{
+						ID:       "123",
+						Requests: 10,
+					}
+					close(ch)
+
+					d.
+						On("Stream", "123").
+						Return((<-chan models.AttackMetrics)(ch), func() {}, nil)
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/attack/123/stream", nil)
+					return d, req
+				},
+				wantCode: http.StatusOK,
+				wantBody: "event:metrics\ndata:{\"id\":\"123\"",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestDispatcherRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+			if !strings.Contains(w.Body.String(), tt.params.wantBody) {
+				t.Errorf("body = %s, want %s", w.Body.String(), tt.params.wantBody)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"net/http"
+	"vegeta-server/internal/cluster"
 	"vegeta-server/internal/dispatcher"
 	"vegeta-server/internal/reporter"
 
@@ -31,6 +32,17 @@ var (
 		)
 	}
 
+	ginErrConflict = func(c *gin.Context, err error) {
+		c.JSON(
+			http.StatusConflict,
+			gin.H{
+				"message": "Conflict",
+				"code":    http.StatusConflict,
+				"error":   err.Error(),
+			},
+		)
+	}
+
 	ginErrInternalServerError = func(c *gin.Context, err error) {
 		c.JSON(
 			http.StatusInternalServerError,
@@ -48,22 +60,33 @@ var (
 type Endpoints struct {
 	dispatcher dispatcher.IDispatcher
 	reporter   reporter.IReporter
+	cluster    cluster.ICluster
 }
 
-// NewEndpoints returns an instance of the Endpoints object
-func NewEndpoints(d dispatcher.IDispatcher, r reporter.IReporter) *Endpoints {
+// NewEndpoints returns an instance of the Endpoints object. The cluster is
+// only set for coordinators.
+func NewEndpoints(d dispatcher.IDispatcher, r reporter.IReporter, c cluster.ICluster) *Endpoints {
 	return &Endpoints{
 		d,
 		r,
+		c,
 	}
 }
 
 // SetupRouter registers the endpoint handlers and returns a pointer to the
-// server instance.
-func SetupRouter(d dispatcher.IDispatcher, r reporter.IReporter) *gin.Engine {
+// server instance. The worker endpoints are only registered for coordinators,
+// with a cluster.
+func SetupRouter(d dispatcher.IDispatcher, r reporter.IReporter, c cluster.ICluster) *gin.Engine {
 	router := gin.Default()
+	router.Use(metricsMiddleware)
 
-	e := NewEndpoints(d, r)
+	e := NewEndpoints(d, r, c)
+
+	// Prometheus metrics endpoint
+	router.GET("/metrics", MetricsEndpoint)
+
+	// Health endpoint, checked by coordinators
+	router.GET("/healthz", HealthEndpoint)
 
 	// api/v1 router group
 	v1 := router.Group("/api/v1")
@@ -71,12 +94,38 @@ func SetupRouter(d dispatcher.IDispatcher, r reporter.IReporter) *gin.Engine {
 		// Attack endpoints
 		v1.POST("/attack", e.PostAttackEndpoint)
 		v1.GET("/attack", e.GetAttackEndpoint)
+		v1.DELETE("/attack", e.DeleteAttackEndpoint)
 		v1.GET("/attack/:attackID", e.GetAttackByIDEndpoint)
+		v1.DELETE("/attack/:attackID", e.DeleteAttackByIDEndpoint)
 		v1.POST("/attack/:attackID/cancel", e.PostAttackByIDCancelEndpoint)
+		v1.GET("/attack/:attackID/stream", e.GetAttackByIDStreamEndpoint)
+		v1.GET("/attack/:attackID/webhooks", e.GetAttackByIDWebhooksEndpoint)
+
+		// Schedule endpoints
+		v1.POST("/schedule", e.PostScheduleEndpoint)
+		v1.GET("/schedule", e.GetScheduleEndpoint)
+		v1.GET("/schedule/:scheduleID", e.GetScheduleByIDEndpoint)
+		v1.DELETE("/schedule/:scheduleID", e.DeleteScheduleByIDEndpoint)
+		v1.POST("/schedule/:scheduleID/pause", e.PostScheduleByIDPauseEndpoint)
+		v1.POST("/schedule/:scheduleID/resume", e.PostScheduleByIDResumeEndpoint)
+
+		// Queue endpoints
+		v1.GET("/queue", e.GetQueueEndpoint)
+
+		// Retention endpoints
+		v1.GET("/retention", e.GetRetentionEndpoint)
 
 		// Report endpoints
 		v1.GET("/report", e.GetReportEndpoint)
 		v1.GET("/report/:attackID", e.GetReportByIDEndpoint)
+		v1.DELETE("/report/:attackID", e.DeleteReportByIDEndpoint)
+
+		// Worker endpoints
+		if c != nil {
+			v1.POST("/workers", e.PostWorkerEndpoint)
+			v1.GET("/workers", e.GetWorkersEndpoint)
+			v1.DELETE("/workers/:workerID", e.DeleteWorkerByIDEndpoint)
+		}
 	}
 
 	return router
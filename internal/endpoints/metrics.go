@@ -0,0 +1,56 @@
+package endpoints
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "vegeta_server",
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency histogram of the API requests served.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"handler", "method", "code"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestDuration)
+}
+
+// metricsMiddleware observes the latency of every API request, labeled by the
+// handler name rather than the path to keep the attack IDs out of the labels.
+func metricsMiddleware(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	httpRequestDuration.WithLabelValues(
+		handlerName(c.HandlerName()),
+		c.Request.Method,
+		strconv.Itoa(c.Writer.Status()),
+	).Observe(time.Since(start).Seconds())
+}
+
+// handlerName trims the package and receiver from a gin handler name,
+// e.g. vegeta-server/internal/endpoints.(*Endpoints).GetReportEndpoint-fm
+func handlerName(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// MetricsEndpoint implements a handler for the GET /metrics endpoint, serving
+// all registered metrics in the Prometheus text exposition format
+var MetricsEndpoint = gin.WrapH(promhttp.Handler())
@@ -0,0 +1,44 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestEndpoints_MetricsEndpoint(t *testing.T) {
+	router := SetupRouter(nil, nil, nil)
+
+	// Serve a request to have it observed
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/unknown", nil)
+	router.ServeHTTP(w, req)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	if !strings.Contains(w.Body.String(), "vegeta_server_http_request_duration_seconds") {
+		t.Errorf("body = %s, want vegeta_server_http_request_duration_seconds", w.Body.String())
+	}
+}
+
+func Test_handlerName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"vegeta-server/internal/endpoints.(*Endpoints).GetReportEndpoint-fm", "GetReportEndpoint"},
+		{"vegeta-server/internal/endpoints.metricsMiddleware", "metricsMiddleware"},
+		{"handler", "handler"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, handlerName(tt.name))
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetQueueEndpoint implements a handler for the GET /api/v1/queue endpoint
+func (e *Endpoints) GetQueueEndpoint(c *gin.Context) {
+	resp := e.dispatcher.Queue()
+
+	c.JSON(http.StatusOK, resp)
+}
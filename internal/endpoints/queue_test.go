@@ -0,0 +1,34 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	dmocks "vegeta-server/internal/dispatcher/mocks"
+	"vegeta-server/models"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestEndpoints_GetQueueEndpoint(t *testing.T) {
+	want := models.QueueResponse{
+		MaxConcurrent: 2,
+		Running:       2,
+		Queued: []models.QueueEntry{
+			{ID: "123", Position: 1, Priority: 1, User: "ci"},
+		},
+	}
+
+	d := &dmocks.IDispatcher{}
+	d.
+		On("Queue").
+		Return(want)
+
+	req, _ := http.NewRequest("GET", "/api/v1/queue", nil)
+	w := setupTestDispatcherRouter(d, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var got models.QueueResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	assert.Equal(t, want, got)
+}
@@ -2,11 +2,26 @@ package endpoints
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"vegeta-server/internal/reporter"
 	"vegeta-server/models"
 	"vegeta-server/pkg/vegeta"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	// compareReportID is the path segment of the GET /api/v1/report/compare
+	// endpoint, which the router cannot register next to the attackID wildcard
+	compareReportID = "compare"
+	// aggregateReportID is the path segment of the GET
+	// /api/v1/report/aggregate endpoint
+	aggregateReportID = "aggregate"
 )
 
 // GetReportEndpoint implements a handler for the GET /api/v1/report endpoint
@@ -31,10 +46,27 @@ func (e *Endpoints) GetReportEndpoint(c *gin.Context) {
 // GetReportByIDEndpoint implements a handler for the GET /api/v1/report/<attackID> endpoint
 func (e *Endpoints) GetReportByIDEndpoint(c *gin.Context) {
 	id := c.Param("attackID")
+	switch id {
+	case compareReportID:
+		e.GetReportCompareEndpoint(c)
+		return
+	case aggregateReportID:
+		e.GetReportAggregateEndpoint(c)
+		return
+	}
 
-	format := vegeta.NewFormat(c.DefaultQuery("format", "json"))
-	bucket := c.DefaultQuery("bucket", vegeta.DefaultBucketString)
-	format.SetMeta("bucket", bucket)
+	format, err := reportFormat(c)
+	if err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	if _, ok := rawContentTypes[format.String()]; ok {
+		writeResults(c, format, func(w io.Writer) error {
+			return e.reporter.WriteInFormat(id, format, w)
+		})
+		return
+	}
 
 	resp, err := e.reporter.GetInFormat(id, format)
 	if err != nil {
@@ -42,23 +74,199 @@ func (e *Endpoints) GetReportByIDEndpoint(c *gin.Context) {
 		return
 	}
 
+	writeReport(c, format, resp)
+}
+
+// GetReportAggregateEndpoint implements a handler for the GET /api/v1/report/aggregate endpoint.
+// The results of the attacks listed in the ids query param, or matching the
+// label selector of the labels query param, are merged into a single report.
+func (e *Endpoints) GetReportAggregateEndpoint(c *gin.Context) {
+	// Attacks listed more than once are only merged once
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	selector, ok := c.GetQuery("labels")
+	if len(ids) == 0 && !ok {
+		ginErrBadRequest(c, fmt.Errorf("attack IDs or a label selector are required"))
+		return
+	}
+	if len(ids) > 0 && ok {
+		ginErrBadRequest(c, fmt.Errorf("attack IDs and a label selector are mutually exclusive"))
+		return
+	}
+	if _, err := models.ParseLabelSelector(selector); err != nil {
+		ginErrBadRequest(c, fmt.Errorf("invalid labels: %s", err))
+		return
+	}
+
+	format, err := reportFormat(c)
+	if err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	if _, ok := rawContentTypes[format.String()]; ok {
+		writeResults(c, format, func(w io.Writer) error {
+			return e.reporter.WriteAggregate(ids, selector, format, w)
+		})
+		return
+	}
+
+	resp, err := e.reporter.Aggregate(ids, selector, format)
+	if err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+
+	writeReport(c, format, resp)
+}
+
+// reportFormat returns the report format set by the format query param, along
+// with the latency percentiles, the histogram bucket, the time series interval
+// and the plot options
+func reportFormat(c *gin.Context) (vegeta.Format, error) {
+	format := vegeta.NewFormat(c.DefaultQuery("format", "json"))
+
+	switch format.String() {
+	case vegeta.JSONFormatString, vegeta.TextFormatString:
+		if percentiles, ok := c.GetQuery("percentiles"); ok {
+			if _, err := vegeta.ParsePercentiles(percentiles); err != nil {
+				return nil, err
+			}
+			format.SetMeta("percentiles", percentiles)
+		}
+	case vegeta.HistogramFormatString:
+		bucket := c.DefaultQuery("bucket", vegeta.DefaultBucketString)
+		format.SetMeta("bucket", bucket)
+	}
+
+	if format.String() == vegeta.TimeseriesFormatString {
+		interval := c.DefaultQuery("interval", vegeta.DefaultIntervalString)
+		if _, err := vegeta.ParseInterval(interval); err != nil {
+			return nil, err
+		}
+		format.SetMeta("interval", interval)
+	}
+
+	if format.String() == vegeta.PlotFormatString {
+		threshold := c.DefaultQuery("threshold", vegeta.DefaultThresholdString)
+		if _, err := vegeta.ParseThreshold(threshold); err != nil {
+			return nil, err
+		}
+		format.SetMeta("threshold", threshold)
+		format.SetMeta("title", c.Query("title"))
+	}
+
+	return format, nil
+}
+
+// rawContentTypes maps the formats of raw results to their content type
+var rawContentTypes = map[string]string{
+	vegeta.BinaryFormatString:    "application/octet-stream",
+	vegeta.CSVFormatString:       "text/csv",
+	vegeta.JSONLinesFormatString: "application/x-ndjson",
+}
+
+// writeResults streams raw results with the content type of their format, as
+// they are read. Errors are only returned as such until the first write, and
+// abort the response afterwards.
+func writeResults(c *gin.Context, format vegeta.Format, write func(io.Writer) error) {
+	c.Header("Content-Type", rawContentTypes[format.String()])
+
+	err := write(c.Writer)
+	if err == nil {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	if c.Writer.Written() {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	c.Writer.Header().Del("Content-Type")
+	ginErrNotFound(c, err)
+}
+
+// writeReport writes a report with the content type of its format
+func writeReport(c *gin.Context, format vegeta.Format, resp []byte) {
 	switch format.String() {
 	case vegeta.JSONFormatString:
 		c.Header("Content-Type", "application/json")
 		var jsonReport models.JSONReportResponse
-		err = json.Unmarshal(resp, &jsonReport)
+		err := json.Unmarshal(resp, &jsonReport)
 		if err != nil {
 			ginErrInternalServerError(c, err)
+			return
 		}
 		c.JSON(http.StatusOK, jsonReport)
 	case vegeta.TextFormatString:
 		c.Header("Content-Type", "text/plain")
 		c.String(http.StatusOK, "%s", resp)
-	case vegeta.BinaryFormatString:
-		c.Header("Content-Type", "application/octet-stream")
-		c.Data(http.StatusOK, "application/octet-stream", resp)
-	case vegeta.HistogramFormatString:
+	case vegeta.HistogramFormatString, vegeta.HDRPlotFormatString:
 		c.Header("Content-Type", "text/plain")
 		c.String(http.StatusOK, "%s", resp)
+	case vegeta.TimeseriesFormatString:
+		c.Data(http.StatusOK, "application/json", resp)
+	case vegeta.PlotFormatString:
+		c.Data(http.StatusOK, "text/html; charset=utf-8", resp)
+	}
+}
+
+// GetReportCompareEndpoint implements a handler for the GET /api/v1/report/compare endpoint.
+// The base and candidate attack IDs are passed as query params, along with
+// optional tolerances overriding the defaults.
+func (e *Endpoints) GetReportCompareEndpoint(c *gin.Context) {
+	base := c.Query("base")
+	candidate := c.Query("candidate")
+	if base == "" || candidate == "" {
+		ginErrBadRequest(c, fmt.Errorf("base and candidate attack IDs are required"))
+		return
+	}
+
+	tol := models.DefaultCompareTolerances
+	for _, t := range []struct {
+		name  string
+		value *float64
+	}{
+		{"latency_increase", &tol.LatencyIncrease},
+		{"success_decrease", &tol.SuccessDecrease},
+		{"throughput_decrease", &tol.ThroughputDecrease},
+	} {
+		v, ok := c.GetQuery(t.name)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			ginErrBadRequest(c, fmt.Errorf("invalid %s tolerance %q", t.name, v))
+			return
+		}
+		*t.value = f
 	}
+
+	// Attacks that did not complete have no report to compare
+	resp, err := e.reporter.Compare(base, candidate, tol)
+	if errors.Cause(err) == reporter.ErrNoResult {
+		ginErrConflict(c, err)
+		return
+	}
+	if err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteReportByIDEndpoint implements a handler for the DELETE /api/v1/report/<attackID> endpoint.
+// Reports are stored along with their attack, which is deleted as well.
+func (e *Endpoints) DeleteReportByIDEndpoint(c *gin.Context) {
+	e.deleteAttack(c, c.Param("attackID"))
 }
@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,14 +11,17 @@ import (
 	"vegeta-server/pkg/vegeta"
 
 	"github.com/gin-gonic/gin/json"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
 
+	dmocks "vegeta-server/internal/dispatcher/mocks"
 	rmock "vegeta-server/internal/reporter/mocks"
 
 	assert "gopkg.in/go-playground/assert.v1"
 )
 
 func setupTestReporterRouter(r reporter.IReporter, req *http.Request) *httptest.ResponseRecorder {
-	router := SetupRouter(nil, r)
+	router := SetupRouter(nil, r, nil)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -193,14 +197,62 @@ func TestEndpoints_GetReportByIDEndpoint(t *testing.T) {
 			},
 		},
 		{
-			name: "OK - binary",
+			name: "OK - percentiles",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					f := vegeta.NewFormat("text")
+					f.SetMeta("percentiles", "99.9,99.99")
+					r.
+						On("GetInFormat", "123", f).
+						Return([]byte{}, nil)
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=text&percentiles=99.9,99.99", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "Bad Request - percentiles",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?percentiles=101", nil)
+
+					return &rmock.IReporter{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "OK - hdrplot",
 			params: params{
 				setup: func() (reporter.IReporter, *http.Request) {
 					r := &rmock.IReporter{}
 					r.
-						On("GetInFormat", "123", vegeta.NewFormat("binary")).
+						On("GetInFormat", "123", vegeta.NewFormat("hdrplot")).
 						Return([]byte{}, nil)
 
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=hdrplot", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "OK - binary",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					r.
+						On("WriteInFormat", "123", vegeta.NewFormat("binary"), mock.Anything).
+						Return(nil)
+
 					// Setup router
 					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=binary", nil)
 
@@ -209,6 +261,120 @@ func TestEndpoints_GetReportByIDEndpoint(t *testing.T) {
 				wantCode: http.StatusOK,
 			},
 		},
+		{
+			name: "OK - csv",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					r.
+						On("WriteInFormat", "123", vegeta.NewFormat("csv"), mock.Anything).
+						Return(nil)
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=csv", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "Not Found - csv",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					r.
+						On("WriteInFormat", "123", vegeta.NewFormat("csv"), mock.Anything).
+						Return(fmt.Errorf("not found"))
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=csv", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "OK - jsonl",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					r.
+						On("WriteInFormat", "123", vegeta.NewFormat("jsonl"), mock.Anything).
+						Return(nil)
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=jsonl", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "OK - timeseries",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					f := vegeta.NewFormat("timeseries")
+					f.SetMeta("interval", "250ms")
+					r.
+						On("GetInFormat", "123", f).
+						Return([]byte("{}"), nil)
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=timeseries&interval=250ms", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "Bad Request - timeseries interval",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=timeseries&interval=0s", nil)
+
+					return &rmock.IReporter{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "OK - plot",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					f := vegeta.NewFormat("plot")
+					f.SetMeta("threshold", "100")
+					f.SetMeta("title", "Checkout")
+					r.
+						On("GetInFormat", "123", f).
+						Return([]byte("<html></html>"), nil)
+
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=plot&threshold=100&title=Checkout", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "Bad Request - plot threshold",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					// Setup router
+					req, _ := http.NewRequest("GET", "/api/v1/report/123?format=plot&threshold=2", nil)
+
+					return &rmock.IReporter{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "OK - histogram",
 			params: params{
@@ -237,3 +403,266 @@ func TestEndpoints_GetReportByIDEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestEndpoints_GetReportByIDEndpoint_Stream(t *testing.T) {
+	r := &rmock.IReporter{}
+	r.
+		On("WriteInFormat", "123", vegeta.NewFormat("csv"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			_, _ = args.Get(2).(io.Writer).Write([]byte("result\n"))
+		}).
+		Return(nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/report/123?format=csv", nil)
+	w := setupTestReporterRouter(r, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "result\n", w.Body.String())
+}
+
+func TestEndpoints_GetReportCompareEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupReporterFunc
+		wantCode int
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Bad Request - Missing candidate",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/report/compare?base=1", nil)
+
+					return &rmock.IReporter{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request - Invalid tolerance",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/report/compare?base=1&candidate=2&latency_increase=ten", nil)
+
+					return &rmock.IReporter{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Not Found",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					r.
+						On("Compare", "1", "2", models.DefaultCompareTolerances).
+						Return(nil, fmt.Errorf("not found"))
+
+					req, _ := http.NewRequest("GET", "/api/v1/report/compare?base=1&candidate=2", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "Conflict - No result",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					r.
+						On("Compare", "1", "2", models.DefaultCompareTolerances).
+						Return(nil, errors.Wrap(errors.Wrap(reporter.ErrNoResult, "attack 2"), "failed to get candidate attack metrics"))
+
+					req, _ := http.NewRequest("GET", "/api/v1/report/compare?base=1&candidate=2", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "OK - with tolerances",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					tol := models.DefaultCompareTolerances
+					tol.LatencyIncrease = 0.05
+					tol.SuccessDecrease = 0
+
+					r := &rmock.IReporter{}
+					r.
+						On("Compare", "1", "2", tol).
+						Return(&models.CompareReportResponse{Pass: true}, nil)
+
+					req, _ := http.NewRequest("GET", "/api/v1/report/compare?base=1&candidate=2&latency_increase=0.05&success_decrease=0", nil) // nolint: lll
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestReporterRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+		})
+	}
+}
+
+func TestEndpoints_GetReportAggregateEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupReporterFunc
+		wantCode int
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Bad Request - Missing IDs and labels",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/report/aggregate?ids=,", nil)
+
+					return &rmock.IReporter{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request - IDs and labels",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/report/aggregate?ids=1&labels=team=payments", nil)
+
+					return &rmock.IReporter{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request - Invalid labels",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					req, _ := http.NewRequest("GET", "/api/v1/report/aggregate?labels==payments", nil)
+
+					return &rmock.IReporter{}, req
+				},
+				wantCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Not Found",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					r.
+						On("Aggregate", []string{"1", "2"}, "", vegeta.NewFormat("text")).
+						Return(nil, fmt.Errorf("not found"))
+
+					req, _ := http.NewRequest("GET", "/api/v1/report/aggregate?ids=1,2&format=text", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "OK - IDs",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					resp, _ := json.Marshal(models.JSONReportResponse{ID: "1,2"})
+					r := &rmock.IReporter{}
+					r.
+						On("Aggregate", []string{"1", "2"}, "", vegeta.NewFormat("json")).
+						Return(resp, nil)
+
+					req, _ := http.NewRequest("GET", "/api/v1/report/aggregate?ids=1,%202", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "OK - labels",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					h := vegeta.NewFormat("histogram")
+					h.SetMeta("bucket", vegeta.DefaultBucketString)
+					r := &rmock.IReporter{}
+					r.
+						On("Aggregate", []string(nil), "team=payments", h).
+						Return([]byte{}, nil)
+
+					req, _ := http.NewRequest("GET", "/api/v1/report/aggregate?labels=team=payments&format=histogram", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "OK - csv with duplicate ids",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					r := &rmock.IReporter{}
+					r.
+						On("WriteAggregate", []string{"1", "2"}, "", vegeta.NewFormat("csv"), mock.Anything).
+						Return(nil)
+
+					req, _ := http.NewRequest("GET", "/api/v1/report/aggregate?ids=1,2,1&format=csv", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+		{
+			name: "OK - plot overlay",
+			params: params{
+				setup: func() (reporter.IReporter, *http.Request) {
+					f := vegeta.NewFormat("plot")
+					f.SetMeta("threshold", vegeta.DefaultThresholdString)
+					f.SetMeta("title", "")
+					r := &rmock.IReporter{}
+					r.
+						On("Aggregate", []string{"1", "2"}, "", f).
+						Return([]byte("<html></html>"), nil)
+
+					req, _ := http.NewRequest("GET", "/api/v1/report/aggregate?ids=1,2&format=plot", nil)
+
+					return r, req
+				},
+				wantCode: http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestReporterRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+		})
+	}
+}
+
+func TestEndpoints_DeleteReportByIDEndpoint(t *testing.T) {
+	d := &dmocks.IDispatcher{}
+	d.
+		On("Get", "123").
+		Return(&models.AttackResponse{ID: "123", Status: models.AttackResponseStatusCompleted}, nil)
+	d.
+		On("Delete", "123", false).
+		Return(nil)
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/report/123", nil)
+	w := setupTestDispatcherRouter(d, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	d.AssertExpectations(t)
+}
@@ -0,0 +1,14 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRetentionEndpoint implements a handler for the GET /api/v1/retention endpoint
+func (e *Endpoints) GetRetentionEndpoint(c *gin.Context) {
+	resp := e.dispatcher.Retention()
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,36 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	dmocks "vegeta-server/internal/dispatcher/mocks"
+	"vegeta-server/models"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestEndpoints_GetRetentionEndpoint(t *testing.T) {
+	want := models.RetentionResponse{
+		MaxAge:       "168h0m0s",
+		MaxCount:     1000,
+		EvictedTotal: 1,
+		LastSweep: &models.RetentionSweep{
+			Evicted:    []string{"123"},
+			FreedBytes: 512,
+		},
+	}
+
+	d := &dmocks.IDispatcher{}
+	d.
+		On("Retention").
+		Return(want)
+
+	req, _ := http.NewRequest("GET", "/api/v1/retention", nil)
+	w := setupTestDispatcherRouter(d, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var got models.RetentionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,106 @@
+package endpoints
+
+import (
+	"net/http"
+	"vegeta-server/models"
+	"vegeta-server/pkg/vegeta"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PostScheduleEndpoint implements a handler for the POST /api/v1/schedule endpoint
+func (e *Endpoints) PostScheduleEndpoint(c *gin.Context) {
+	var scheduleParams models.ScheduleParams
+	if err := c.ShouldBindJSON(&scheduleParams); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	// Check the targets, labels, ramp and assertions up front, rather than failing each attack later
+	if err := vegeta.ValidateTargets(scheduleParams.AttackParams); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	if err := models.ValidateLabels(scheduleParams.Labels); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	if err := vegeta.ValidateRamp(scheduleParams.AttackParams); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	if err := vegeta.ValidateAssertions(scheduleParams.AttackParams); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	// Submit the schedule
+	resp, err := e.dispatcher.Schedule(scheduleParams)
+	if err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetScheduleEndpoint implements a handler for the GET /api/v1/schedule endpoint
+func (e *Endpoints) GetScheduleEndpoint(c *gin.Context) {
+	resp := e.dispatcher.ListSchedules()
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetScheduleByIDEndpoint implements a handler for the GET /api/v1/schedule/<scheduleID> endpoint
+func (e *Endpoints) GetScheduleByIDEndpoint(c *gin.Context) {
+	id := c.Param("scheduleID")
+	resp, err := e.dispatcher.GetSchedule(id)
+	if err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// PostScheduleByIDPauseEndpoint implements a handler for the POST /api/v1/schedule/<scheduleID>/pause endpoint
+func (e *Endpoints) PostScheduleByIDPauseEndpoint(c *gin.Context) {
+	e.pauseSchedule(c, true)
+}
+
+// PostScheduleByIDResumeEndpoint implements a handler for the POST /api/v1/schedule/<scheduleID>/resume endpoint
+func (e *Endpoints) PostScheduleByIDResumeEndpoint(c *gin.Context) {
+	e.pauseSchedule(c, false)
+}
+
+// DeleteScheduleByIDEndpoint implements a handler for the DELETE /api/v1/schedule/<scheduleID> endpoint.
+// Attacks already created by the schedule are not deleted.
+func (e *Endpoints) DeleteScheduleByIDEndpoint(c *gin.Context) {
+	id := c.Param("scheduleID")
+	if err := e.dispatcher.DeleteSchedule(id); err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (e *Endpoints) pauseSchedule(c *gin.Context, pause bool) {
+	id := c.Param("scheduleID")
+	_, err := e.dispatcher.GetSchedule(id)
+	if err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+
+	err = e.dispatcher.PauseSchedule(id, pause)
+	if err != nil {
+		ginErrInternalServerError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,290 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"vegeta-server/internal/dispatcher"
+	dmocks "vegeta-server/internal/dispatcher/mocks"
+	"vegeta-server/models"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestEndpoints_PostScheduleEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupDispatcherFunc
+		wantCode int
+	}
+	scheduleParams := models.ScheduleParams{
+		AttackParams: models.AttackParams{
+			Rate: 1,
+			Target: models.Target{
+				Method: "GET",
+				URL:    "localhost:80/api/v1/",
+				Scheme: "http",
+			},
+			Duration: "1s",
+		},
+		Cron: "0 2 * * *",
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Bad Request - Missing Duration",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					p := scheduleParams
+					p.Duration = ""
+					bScheduleParamsBody, _ := json.Marshal(p)
+
+					req, _ := http.NewRequest("POST", "/api/v1/schedule", strings.NewReader(string(bScheduleParamsBody)))
+					return new(dmocks.IDispatcher), req
+				},
+				http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Bad Request - Invalid schedule",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := new(dmocks.IDispatcher)
+
+					d.
+						On("Schedule", scheduleParams).
+						Return(nil, fmt.Errorf("failed to parse cron expression"))
+					bScheduleParamsBody, _ := json.Marshal(scheduleParams)
+
+					req, _ := http.NewRequest("POST", "/api/v1/schedule", strings.NewReader(string(bScheduleParamsBody)))
+					return d, req
+				},
+				http.StatusBadRequest,
+			},
+		},
+		{
+			name: "OK",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := new(dmocks.IDispatcher)
+
+					d.
+						On("Schedule", scheduleParams).
+						Return(&models.ScheduleResponse{ID: "123"}, nil)
+					bScheduleParamsBody, _ := json.Marshal(scheduleParams)
+
+					req, _ := http.NewRequest("POST", "/api/v1/schedule", strings.NewReader(string(bScheduleParamsBody)))
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestDispatcherRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+		})
+	}
+}
+
+func TestEndpoints_GetScheduleEndpoint(t *testing.T) {
+	d := &dmocks.IDispatcher{}
+	d.
+		On("ListSchedules").
+		Return([]*models.ScheduleResponse{})
+
+	req, _ := http.NewRequest("GET", "/api/v1/schedule", nil)
+	w := setupTestDispatcherRouter(d, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestEndpoints_GetScheduleByIDEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupDispatcherFunc
+		wantCode int
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Not Found",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("GetSchedule", "123").
+						Return(nil, fmt.Errorf("not found"))
+
+					req, _ := http.NewRequest("GET", "/api/v1/schedule/123", nil)
+					return d, req
+				},
+				http.StatusNotFound,
+			},
+		},
+		{
+			name: "OK",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("GetSchedule", "123").
+						Return(&models.ScheduleResponse{ID: "123"}, nil)
+
+					req, _ := http.NewRequest("GET", "/api/v1/schedule/123", nil)
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestDispatcherRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+		})
+	}
+}
+
+func TestEndpoints_PostScheduleByIDPauseEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupDispatcherFunc
+		wantCode int
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Not Found",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("GetSchedule", "123").
+						Return(nil, fmt.Errorf("not found"))
+
+					req, _ := http.NewRequest("POST", "/api/v1/schedule/123/pause", nil)
+					return d, req
+				},
+				http.StatusNotFound,
+			},
+		},
+		{
+			name: "Internal Server Error",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("GetSchedule", "123").
+						Return(nil, nil)
+					d.
+						On("PauseSchedule", "123", true).
+						Return(fmt.Errorf("schedule is done"))
+
+					req, _ := http.NewRequest("POST", "/api/v1/schedule/123/pause", nil)
+					return d, req
+				},
+				http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "OK - Pause",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("GetSchedule", "123").
+						Return(nil, nil)
+					d.
+						On("PauseSchedule", "123", true).
+						Return(nil)
+
+					req, _ := http.NewRequest("POST", "/api/v1/schedule/123/pause", nil)
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+		{
+			name: "OK - Resume",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("GetSchedule", "123").
+						Return(nil, nil)
+					d.
+						On("PauseSchedule", "123", false).
+						Return(nil)
+
+					req, _ := http.NewRequest("POST", "/api/v1/schedule/123/resume", nil)
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestDispatcherRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+		})
+	}
+}
+
+func TestEndpoints_DeleteScheduleByIDEndpoint(t *testing.T) {
+	type params struct {
+		setup    setupDispatcherFunc
+		wantCode int
+	}
+	tests := []struct {
+		name   string
+		params params
+	}{
+		{
+			name: "Not Found",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("DeleteSchedule", "123").
+						Return(fmt.Errorf("not found"))
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/schedule/123", nil)
+					return d, req
+				},
+				http.StatusNotFound,
+			},
+		},
+		{
+			name: "OK",
+			params: params{
+				func() (dispatcher.IDispatcher, *http.Request) {
+					d := &dmocks.IDispatcher{}
+					d.
+						On("DeleteSchedule", "123").
+						Return(nil)
+
+					req, _ := http.NewRequest("DELETE", "/api/v1/schedule/123", nil)
+					return d, req
+				},
+				http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := setupTestDispatcherRouter(tt.params.setup())
+			gotCode := w.Code
+			assert.Equal(t, tt.params.wantCode, gotCode)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package endpoints
+
+import (
+	"net/http"
+	"vegeta-server/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthEndpoint implements a handler for the GET /healthz endpoint
+func HealthEndpoint(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// PostWorkerEndpoint implements a handler for the POST /api/v1/workers endpoint.
+// Workers register with the coordinator, and register again periodically.
+func (e *Endpoints) PostWorkerEndpoint(c *gin.Context) {
+	var registration models.WorkerRegistration
+	if err := c.ShouldBindJSON(&registration); err != nil {
+		ginErrBadRequest(c, err)
+		return
+	}
+
+	resp := e.cluster.Register(registration.URL)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWorkersEndpoint implements a handler for the GET /api/v1/workers endpoint
+func (e *Endpoints) GetWorkersEndpoint(c *gin.Context) {
+	resp := e.cluster.Workers()
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteWorkerByIDEndpoint implements a handler for the DELETE /api/v1/workers/<workerID> endpoint
+func (e *Endpoints) DeleteWorkerByIDEndpoint(c *gin.Context) {
+	if err := e.cluster.Deregister(c.Param("workerID")); err != nil {
+		ginErrNotFound(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,86 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"vegeta-server/internal/cluster"
+	cmocks "vegeta-server/internal/cluster/mocks"
+	"vegeta-server/models"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func setupTestClusterRouter(c cluster.ICluster, req *http.Request) *httptest.ResponseRecorder {
+	router := SetupRouter(nil, nil, c)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestEndpoints_HealthEndpoint(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	w := setupTestClusterRouter(nil, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestEndpoints_PostWorkerEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"OK", `{"url": "http://worker-1:80"}`, http.StatusOK},
+		{"Bad URL", `{"url": "worker-1"}`, http.StatusBadRequest},
+		{"Missing URL", `{}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cmocks.ICluster{}
+			c.
+				On("Register", "http://worker-1:80").
+				Return(models.Worker{ID: "123", URL: "http://worker-1:80", Healthy: true})
+
+			req, _ := http.NewRequest("POST", "/api/v1/workers", strings.NewReader(tt.body))
+			w := setupTestClusterRouter(c, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestEndpoints_GetWorkersEndpoint(t *testing.T) {
+	c := &cmocks.ICluster{}
+	c.
+		On("Workers").
+		Return([]models.Worker{{ID: "123", URL: "http://worker-1:80", Healthy: true}})
+
+	req, _ := http.NewRequest("GET", "/api/v1/workers", nil)
+	w := setupTestClusterRouter(c, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Standalone servers have no worker endpoints
+	w = setupTestClusterRouter(nil, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestEndpoints_DeleteWorkerByIDEndpoint(t *testing.T) {
+	c := &cmocks.ICluster{}
+	c.
+		On("Deregister", "123").
+		Return(nil)
+	c.
+		On("Deregister", "456").
+		Return(fmt.Errorf("not found"))
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/workers/123", nil)
+	w := setupTestClusterRouter(c, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/workers/456", nil)
+	w = setupTestClusterRouter(c, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
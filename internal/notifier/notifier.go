@@ -0,0 +1,271 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+	"vegeta-server/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of the payload, when
+	// a webhook secret is configured
+	SignatureHeader = "X-Vegeta-Signature"
+	// EventHeader carries the attack status of the event
+	EventHeader = "X-Vegeta-Event"
+	// DeliveryHeader carries the event ID, shared by the delivery retries
+	DeliveryHeader = "X-Vegeta-Delivery"
+)
+
+var (
+	// maxAttempts is the number of attempts at delivering an event to a webhook
+	maxAttempts = 5
+	// backoff is the wait before the first retry, doubled for every retry
+	backoff = time.Second
+	// idleTimeout is the time after which the delivery worker of an attack
+	// without events exits
+	idleTimeout = time.Minute
+	// maxDeliveries is the number of deliveries kept in the log of an attack
+	maxDeliveries = 100
+	// maxLogs is the number of attacks whose delivery log is kept, the
+	// oldest logs being dropped first
+	maxLogs = 1000
+)
+
+// INotifier provides an interface for attack status notification operations.
+type INotifier interface {
+	// Enabled reports whether any webhook is notified of the events of an
+	// attack with the webhooks, along with the server webhooks
+	Enabled([]string) bool
+	// Notify the webhooks of an attack status transition. The events of an
+	// attack are delivered in order.
+	Notify(models.WebhookEvent, []string)
+	// Deliveries returns the webhook delivery log of an attack
+	Deliveries(string) []models.WebhookDelivery
+	// Forget the webhook delivery log of a deleted attack
+	Forget(string)
+}
+
+type job struct {
+	event models.WebhookEvent
+	urls  []string
+}
+
+type notifier struct {
+	mu         *sync.Mutex
+	urls       []string
+	secret     string
+	client     *http.Client
+	queues     map[string]chan job
+	deliveries map[string][]models.WebhookDelivery
+	// logs lists the IDs of the attacks with a delivery log, oldest first
+	logs []string
+}
+
+// NewNotifier returns an instance of the notifier object. The urls are
+// notified of the status transitions of all attacks, and the payloads are
+// signed with the secret, if set.
+func NewNotifier(urls []string, secret string) *notifier { // nolint: golint
+	return &notifier{
+		&sync.Mutex{},
+		urls,
+		secret,
+		&http.Client{Timeout: 10 * time.Second},
+		make(map[string]chan job),
+		make(map[string][]models.WebhookDelivery),
+		make([]string, 0),
+	}
+}
+
+// Enabled reports whether any webhook is notified of the events of an attack
+// with the urls
+func (n *notifier) Enabled(urls []string) bool {
+	return len(n.urls) > 0 || len(urls) > 0
+}
+
+// Notify queues the event for delivery to the server and attack webhooks
+func (n *notifier) Notify(event models.WebhookEvent, urls []string) {
+	all := append(append([]string{}, n.urls...), urls...)
+	if len(all) == 0 {
+		return
+	}
+
+	id := event.Attack.ID
+
+	// The queue is only removed by its worker while holding the lock, so a
+	// queued job is always picked up.
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	q, ok := n.queues[id]
+	if !ok {
+		q = make(chan job, 10)
+		n.queues[id] = q
+		go n.work(id, q)
+	}
+
+	select {
+	case q <- job{event, all}:
+	default:
+		n.log(id).Error("webhook queue full, dropping event")
+	}
+}
+
+// Deliveries returns a copy of the webhook delivery log of an attack
+func (n *notifier) Deliveries(id string) []models.WebhookDelivery {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]models.WebhookDelivery{}, n.deliveries[id]...)
+}
+
+// Forget drops the webhook delivery log of an attack. Events still queued
+// are delivered, and logged anew.
+func (n *notifier) Forget(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, ok := n.deliveries[id]; !ok {
+		return
+	}
+
+	delete(n.deliveries, id)
+	for i, logged := range n.logs {
+		if logged == id {
+			n.logs = append(n.logs[:i], n.logs[i+1:]...)
+			break
+		}
+	}
+}
+
+// work delivers the events of an attack in order, until no event is queued
+// for idleTimeout
+func (n *notifier) work(id string, q chan job) {
+	for {
+		select {
+		case j := <-q:
+			n.deliver(j)
+		case <-time.After(idleTimeout):
+			n.mu.Lock()
+			if len(q) == 0 {
+				delete(n.queues, id)
+				n.mu.Unlock()
+				return
+			}
+			n.mu.Unlock()
+		}
+	}
+}
+
+func (n *notifier) deliver(j job) {
+	body, err := json.Marshal(j.event)
+	if err != nil {
+		n.log(j.event.Attack.ID).WithError(err).Error("failed to marshal webhook event")
+		return
+	}
+
+	for _, url := range j.urls {
+		wait := backoff
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			d := n.post(url, j.event, body)
+			d.Attempt = attempt
+			n.record(j.event.Attack.ID, d)
+
+			if d.Success {
+				break
+			}
+			if attempt < maxAttempts {
+				time.Sleep(wait)
+				wait *= 2
+			}
+		}
+	}
+}
+
+func (n *notifier) post(url string, event models.WebhookEvent, body []byte) models.WebhookDelivery {
+	d := models.WebhookDelivery{
+		EventID:   event.ID,
+		Status:    event.Status,
+		URL:       url,
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event.Status))
+	req.Header.Set(DeliveryHeader, event.ID)
+	if n.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	d.StatusCode = resp.StatusCode
+	d.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !d.Success {
+		d.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return d
+}
+
+func (n *notifier) record(id string, d models.WebhookDelivery) {
+	if !d.Success {
+		n.log(id).WithField("URL", d.URL).Warnf("webhook delivery failed: %s", d.Error)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// The logs of the oldest attacks are dropped, as they are otherwise only
+	// dropped when attacks are deleted
+	if _, ok := n.deliveries[id]; !ok {
+		n.logs = append(n.logs, id)
+		if len(n.logs) > maxLogs {
+			delete(n.deliveries, n.logs[0])
+			n.logs = n.logs[1:]
+		}
+	}
+
+	deliveries := append(n.deliveries[id], d)
+	if len(deliveries) > maxDeliveries {
+		deliveries = deliveries[len(deliveries)-maxDeliveries:]
+	}
+	n.deliveries[id] = deliveries
+}
+
+func (n *notifier) log(id string) *log.Entry {
+	return log.WithFields(log.Fields{
+		"component": "notifier",
+		"ID":        id,
+	})
+}
+
+// Sign returns the signature of a webhook payload, as set in the
+// X-Vegeta-Signature header: "sha256=" followed by the hex encoded
+// HMAC-SHA256 of the payload.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,174 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+	"vegeta-server/models"
+)
+
+func init() {
+	backoff = time.Millisecond
+}
+
+// webhookServer records the events it receives, failing the first
+// failures requests
+func webhookServer(t *testing.T, failures int) (*httptest.Server, func() []models.WebhookEvent) {
+	var mu sync.Mutex
+	var events []models.WebhookEvent
+	requests := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		if requests <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		if got, want := r.Header.Get(SignatureHeader), Sign("secret", body); got != want {
+			t.Errorf("signature = %q, want %q", got, want)
+		}
+
+		var event models.WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Error(err)
+		}
+		if got := r.Header.Get(DeliveryHeader); got != event.ID {
+			t.Errorf("delivery header = %q, want %q", got, event.ID)
+		}
+		events = append(events, event)
+	}))
+
+	return srv, func() []models.WebhookEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]models.WebhookEvent{}, events...)
+	}
+}
+
+func event(id string, status models.AttackStatus) models.WebhookEvent {
+	return models.WebhookEvent{
+		ID:     id + string(status),
+		Status: status,
+		Attack: models.AttackInfo{ID: id, Status: status},
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_notifier_Notify(t *testing.T) {
+	srv, events := webhookServer(t, 2)
+	defer srv.Close()
+
+	n := NewNotifier([]string{srv.URL}, "secret")
+	n.Notify(event("123", models.AttackResponseStatusRunning), nil)
+	n.Notify(event("123", models.AttackResponseStatusCompleted), nil)
+
+	waitFor(t, func() bool { return len(events()) == 2 })
+
+	var got []models.AttackStatus
+	for _, e := range events() {
+		got = append(got, e.Status)
+	}
+	want := []models.AttackStatus{models.AttackResponseStatusRunning, models.AttackResponseStatusCompleted}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered events = %v, want %v", got, want)
+	}
+
+	waitFor(t, func() bool { return len(n.Deliveries("123")) == 4 })
+
+	deliveries := n.Deliveries("123")
+	for i, d := range deliveries {
+		wantAttempt, wantSuccess := i+1, i == 2
+		if i == 3 {
+			wantAttempt, wantSuccess = 1, true
+		}
+		if d.Attempt != wantAttempt || d.Success != wantSuccess {
+			t.Errorf("delivery %d = %v, want attempt %d and success %v", i, d, wantAttempt, wantSuccess)
+		}
+	}
+	if deliveries[2].StatusCode != http.StatusOK || deliveries[0].StatusCode != http.StatusInternalServerError {
+		t.Errorf("unexpected delivery status codes %v", deliveries)
+	}
+
+	n.Forget("123")
+	if got := n.Deliveries("123"); len(got) != 0 {
+		t.Errorf("Deliveries() = %v, want none once forgotten", got)
+	}
+}
+
+func Test_notifier_Notify_GiveUp(t *testing.T) {
+	srv, events := webhookServer(t, maxAttempts)
+	defer srv.Close()
+
+	n := NewNotifier(nil, "secret")
+	n.Notify(event("123", models.AttackResponseStatusFailed), []string{srv.URL})
+
+	waitFor(t, func() bool { return len(n.Deliveries("123")) == maxAttempts })
+
+	for _, d := range n.Deliveries("123") {
+		if d.Success || d.Error == "" {
+			t.Errorf("delivery = %v, want failure", d)
+		}
+	}
+	if len(events()) != 0 {
+		t.Errorf("delivered events = %v, want none", events())
+	}
+}
+
+func Test_notifier_Notify_NoWebhooks(t *testing.T) {
+	n := NewNotifier(nil, "")
+	if n.Enabled(nil) || !n.Enabled([]string{"http://localhost/hook"}) {
+		t.Error("notifier enabled without webhooks")
+	}
+	n.Notify(event("123", models.AttackResponseStatusRunning), nil)
+
+	if len(n.queues) != 0 {
+		t.Errorf("notifier queues = %v, want none", n.queues)
+	}
+}
+
+func Test_notifier_record_Bounded(t *testing.T) {
+	defer func(max int) { maxLogs = max }(maxLogs)
+	maxLogs = 2
+
+	n := NewNotifier(nil, "")
+	for _, id := range []string{"1", "2", "3"} {
+		n.record(id, models.WebhookDelivery{Success: true})
+	}
+
+	// The log of the oldest attack is dropped
+	if len(n.Deliveries("1")) != 0 || len(n.Deliveries("2")) != 1 || len(n.Deliveries("3")) != 1 {
+		t.Errorf("notifier deliveries = %v", n.deliveries)
+	}
+
+	n.Forget("2")
+	n.record("4", models.WebhookDelivery{Success: true})
+	if !reflect.DeepEqual(n.logs, []string{"3", "4"}) || len(n.deliveries) != 2 {
+		t.Errorf("notifier logs = %v, deliveries = %v", n.logs, n.deliveries)
+	}
+}
+
+func TestSign(t *testing.T) {
+	want := "sha256=734cc62f32841568f45715aeb9f4d7891324e6d948e4c6c60c0621cdac48623a"
+	if got := Sign("secret", []byte("hello world")); got != want {
+		t.Errorf("Sign() = %v, want %v", got, want)
+	}
+}
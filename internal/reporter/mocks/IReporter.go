@@ -2,7 +2,9 @@
 
 package mocks
 
+import io "io"
 import mock "github.com/stretchr/testify/mock"
+import models "vegeta-server/models"
 
 import vegeta "vegeta-server/pkg/vegeta"
 
@@ -11,6 +13,52 @@ type IReporter struct {
 	mock.Mock
 }
 
+// Aggregate provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IReporter) Aggregate(_a0 []string, _a1 string, _a2 vegeta.Format) ([]byte, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func([]string, string, vegeta.Format) []byte); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string, string, vegeta.Format) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Compare provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IReporter) Compare(_a0 string, _a1 string, _a2 models.CompareTolerances) (*models.CompareReportResponse, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *models.CompareReportResponse
+	if rf, ok := ret.Get(0).(func(string, string, models.CompareTolerances) *models.CompareReportResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CompareReportResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, models.CompareTolerances) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: _a0
 func (_m *IReporter) Delete(_a0 string) error {
 	ret := _m.Called(_a0)
@@ -86,3 +134,31 @@ func (_m *IReporter) GetInFormat(_a0 string, _a1 vegeta.Format) ([]byte, error)
 
 	return r0, r1
 }
+
+// WriteAggregate provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IReporter) WriteAggregate(_a0 []string, _a1 string, _a2 vegeta.Format, _a3 io.Writer) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, string, vegeta.Format, io.Writer) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteInFormat provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IReporter) WriteInFormat(_a0 string, _a1 vegeta.Format, _a2 io.Writer) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, vegeta.Format, io.Writer) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -3,12 +3,18 @@ package reporter
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"strings"
 	"vegeta-server/models"
 	"vegeta-server/pkg/vegeta"
 
 	"github.com/pkg/errors"
 )
 
+// ErrNoResult is the cause of the errors of reports of attacks without a
+// result, i.e. attacks that did not complete
+var ErrNoResult = errors.New("no result")
+
 // IReporter provides an interface for all report generation operations.
 type IReporter interface {
 	// Get report in (default) JSON format
@@ -16,8 +22,23 @@ type IReporter interface {
 	// GetAll gets all reports in (default) JSON format
 	GetAll() [][]byte
 
-	// Get report in specified format (supported: JSON/Histogram/Text
+	// Get report in specified format (supported: JSON/Histogram/Text)
 	GetInFormat(string, vegeta.Format) ([]byte, error)
+	// WriteInFormat writes the raw result of an attack to the writer in the
+	// binary, CSV or JSON-lines format, as it is read
+	WriteInFormat(string, vegeta.Format, io.Writer) error
+
+	// Aggregate the results of the attacks with the IDs, or matching the label
+	// selector, into a single report in the specified format
+	Aggregate([]string, string, vegeta.Format) ([]byte, error)
+	// WriteAggregate writes the merged raw results of the attacks with the
+	// IDs, or matching the label selector, to the writer in the binary, CSV
+	// or JSON-lines format, as they are read
+	WriteAggregate([]string, string, vegeta.Format, io.Writer) error
+
+	// Compare the report of a candidate attack with a base attack report,
+	// against the tolerances
+	Compare(string, string, models.CompareTolerances) (*models.CompareReportResponse, error)
 
 	// Delete report from store
 	Delete(string) error
@@ -41,15 +62,7 @@ func (r *reporter) Get(id string) ([]byte, error) {
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to get attack with ID %s", id))
 	}
 
-	result := attack.Result
-	report, err := vegeta.CreateReportFromReader(
-		bytes.NewBuffer(result), attack.ID,
-		vegeta.NewFormat(vegeta.JSONFormatString),
-	)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create report from reader")
-	}
-	return report, nil
+	return r.report(attack, vegeta.NewFormat(vegeta.JSONFormatString))
 }
 
 // GetAll returns a list of attack reports in byte array format
@@ -59,15 +72,20 @@ func (r *reporter) GetAll() [][]byte {
 	reports := make([][]byte, 0)
 	for _, attack := range attacks {
 		// Canceled attacks will have a nil result field
-		if attack.Result == nil {
+		if attack.ResultLen() == 0 {
 			continue
 		}
 
+		// Stores may omit the results when listing attacks
+		if resultReader(attack) == nil {
+			var err error
+			if attack, err = r.db.GetByID(attack.ID); err != nil {
+				continue
+			}
+		}
+
 		// Create report for all other attacks
-		report, err := vegeta.CreateReportFromReader(
-			bytes.NewBuffer(attack.Result), attack.ID,
-			vegeta.NewFormat(vegeta.JSONFormatString),
-		)
+		report, err := r.report(attack, vegeta.NewFormat(vegeta.JSONFormatString))
 		if err != nil {
 			continue
 		}
@@ -83,18 +101,158 @@ func (r *reporter) GetInFormat(id string, format vegeta.Format) ([]byte, error)
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to get attack with ID %s", id))
 	}
 
-	result := attack.Result
+	return r.report(attack, format)
+}
+
+// WriteInFormat writes the raw result of an attack to the writer in the
+// binary, CSV or JSON-lines format. Binary results are copied as is.
+func (r *reporter) WriteInFormat(id string, format vegeta.Format, w io.Writer) error {
+	attack, err := r.db.GetByID(id)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to get attack with ID %s", id))
+	}
+
+	result := resultReader(attack)
+	if result == nil {
+		result = bytes.NewReader(nil)
+	}
+
 	if format.String() == vegeta.BinaryFormatString {
-		return result, nil
+		if _, err = io.Copy(w, result); err != nil {
+			return errors.Wrap(err, "failed to write result")
+		}
+		return nil
 	}
 
-	report, err := vegeta.CreateReportFromReader(bytes.NewBuffer(result), attack.ID, format)
+	return vegeta.WriteResults([]io.Reader{result}, w, format)
+}
+
+// report creates the report of an attack in the specified format, along with
+// the attack labels and description
+func (r *reporter) report(attack models.AttackDetails, format vegeta.Format) ([]byte, error) {
+	result := resultReader(attack)
+	if result == nil {
+		result = bytes.NewReader(nil)
+	}
+
+	report, err := vegeta.CreateReportFromReader(result, attack.ID, format)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create report from reader")
 	}
+
+	report, err = vegeta.AddLabels(report, attack.Params, format)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to add labels to report")
+	}
 	return report, nil
 }
 
+// Aggregate returns the report of the merged results of several attacks. All
+// attacks listed by ID must have a result, while the attacks matching the
+// label selector without a result are skipped.
+func (r *reporter) Aggregate(ids []string, selector string, format vegeta.Format) ([]byte, error) {
+	readers, ids, err := r.aggregate(ids, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := vegeta.CreateReportFromReaders(readers, strings.Join(ids, ","), format)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create report from readers")
+	}
+	return report, nil
+}
+
+// WriteAggregate writes the merged raw results of several attacks to the
+// writer in the binary, CSV or JSON-lines format, selected the same way as
+// with Aggregate
+func (r *reporter) WriteAggregate(ids []string, selector string, format vegeta.Format, w io.Writer) error {
+	readers, _, err := r.aggregate(ids, selector)
+	if err != nil {
+		return err
+	}
+
+	return vegeta.WriteResults(readers, w, format)
+}
+
+// aggregate returns the readers of the results of the attacks with the IDs,
+// or matching the label selector, along with their IDs
+func (r *reporter) aggregate(ids []string, selector string) ([]io.Reader, []string, error) {
+	if len(ids) == 0 {
+		filters := models.FilterParams{
+			"status": string(models.AttackResponseStatusCompleted),
+			"labels": selector,
+		}
+		for _, attack := range r.db.GetAll(filters) {
+			if attack.ResultLen() > 0 {
+				ids = append(ids, attack.ID)
+			}
+		}
+		if len(ids) == 0 {
+			return nil, nil, fmt.Errorf("no completed attack matches labels %q", selector)
+		}
+	}
+
+	readers := make([]io.Reader, 0, len(ids))
+	for _, id := range ids {
+		attack, err := r.db.GetByID(id)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, fmt.Sprintf("failed to get attack with ID %s", id))
+		}
+		result := resultReader(attack)
+		if result == nil {
+			return nil, nil, fmt.Errorf("attack %s has no result", id)
+		}
+		readers = append(readers, result)
+	}
+
+	return readers, ids, nil
+}
+
+// Compare returns the comparison of a candidate attack with its base attack
+func (r *reporter) Compare(baseID, candidateID string, tol models.CompareTolerances) (*models.CompareReportResponse, error) { // nolint: lll
+	base, err := r.metrics(baseID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get base attack metrics")
+	}
+
+	candidate, err := r.metrics(candidateID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get candidate attack metrics")
+	}
+
+	resp := vegeta.Compare(base, candidate, tol)
+	return &resp, nil
+}
+
+func (r *reporter) metrics(id string) (models.AttackMetrics, error) {
+	attack, err := r.db.GetByID(id)
+	if err != nil {
+		return models.AttackMetrics{}, errors.Wrap(err, fmt.Sprintf("failed to get attack with ID %s", id))
+	}
+
+	// Only completed attacks have a result
+	result := resultReader(attack)
+	if result == nil {
+		return models.AttackMetrics{}, errors.Wrap(ErrNoResult, fmt.Sprintf("attack %s", id))
+	}
+
+	return vegeta.NewAttackMetricsFromReader(result, id)
+}
+
+// resultReader returns a reader of the result of an attack, read lazily from
+// its spool file unless the store holds the result itself, or nil if the
+// attack has no result
+func resultReader(attack models.AttackDetails) io.Reader {
+	if attack.Result != nil {
+		return bytes.NewReader(attack.Result)
+	}
+	if attack.ResultPath != "" {
+		return vegeta.SpoolReader(attack.ResultPath)
+	}
+	return nil
+}
+
 // Delete removes a report from the storage
 func (r *reporter) Delete(id string) error {
 	return r.db.Delete(id)
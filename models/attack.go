@@ -1,6 +1,10 @@
 package models
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,9 +16,21 @@ type AttackInfo struct {
 	// Status captures the attack status in the scheduler pipeline
 	Status AttackStatus `json:"status,omitempty"`
 	// Params captures the attack parameters
-	Params    AttackParams `json:"params,omitempty"`
-	CreatedAt string       `json:"created_at"`
-	UpdatedAt string       `json:"updated_at"`
+	Params AttackParams `json:"params,omitempty"`
+	// ScheduleID is the ID of the schedule that created the attack, if any
+	ScheduleID string `json:"schedule_id,omitempty"`
+	// Verdict of the attack assertions, set once an attack with assertions
+	// completes
+	Verdict *Verdict `json:"verdict,omitempty"`
+	// Error captures why the attack failed, set for failed attacks
+	Error *AttackError `json:"error,omitempty"`
+	// Cancellation captures who canceled the attack, set for canceled attacks
+	Cancellation *AttackCancellation `json:"cancellation,omitempty"`
+	// QueuePosition is the 1-based position of a scheduled attack in the
+	// dispatcher queue, zero once the attack runs
+	QueuePosition int    `json:"queue_position,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
 }
 
 // AttackDetails captures the AttackInfo for COMPLETED attacks,
@@ -22,6 +38,21 @@ type AttackInfo struct {
 type AttackDetails struct {
 	AttackInfo
 	Result []byte `json:"result,omitempty"`
+	// ResultSize is the size of the result, set by stores that omit the
+	// result when listing attacks, and along with the result path
+	ResultSize int64 `json:"result_size,omitempty"`
+	// ResultPath is the path of the spool file the result is read from
+	// lazily, instead of being held in Result
+	ResultPath string `json:"result_path,omitempty"`
+}
+
+// ResultLen returns the size of the attack result, whether or not the result
+// was loaded
+func (a AttackDetails) ResultLen() int64 {
+	if a.Result != nil {
+		return int64(len(a.Result))
+	}
+	return a.ResultSize
 }
 
 // FilterParams defines a map structure for the filter parameters received via
@@ -48,6 +79,13 @@ func StatusFilter(status string) Filter {
 	}
 }
 
+// parseFilterTime parses the time of the created and updated filters, in the
+// local time zone
+func parseFilterTime(d string) (time.Time, error) {
+	const layoutUser = "2006-01-02 15:04:05"
+	return time.ParseInLocation(layoutUser, d, time.Local)
+}
+
 // CreationBeforeFilter implements an attack created_before filter
 // in the Filter function format
 func CreationBeforeFilter(d string) Filter {
@@ -55,8 +93,7 @@ func CreationBeforeFilter(d string) Filter {
 		if d == "" {
 			return true
 		}
-		const layoutUser = "2006-01-02 15:04:05"
-		t, err := time.ParseInLocation(layoutUser, d, time.Local)
+		t, err := parseFilterTime(d)
 		// If parsing failed, don't filter
 		if err != nil {
 			return true
@@ -75,8 +112,7 @@ func CreationAfterFilter(d string) Filter {
 		if d == "" {
 			return true
 		}
-		const layoutUser = "2006-01-02 15:04:05"
-		t, err := time.ParseInLocation(layoutUser, d, time.Local)
+		t, err := parseFilterTime(d)
 		// If parsing failed, don't filter
 		if err != nil {
 			return true
@@ -87,3 +123,171 @@ func CreationAfterFilter(d string) Filter {
 		return attackTime.After(t)
 	}
 }
+
+// UpdateBeforeFilter implements an attack updated_before filter
+// in the Filter function format
+func UpdateBeforeFilter(d string) Filter {
+	return func(a AttackDetails) bool {
+		if d == "" {
+			return true
+		}
+		t, err := parseFilterTime(d)
+		// If parsing failed, don't filter
+		if err != nil {
+			return true
+		}
+
+		attackTime, _ := time.Parse(time.RFC1123, a.UpdatedAt)
+
+		return attackTime.Before(t)
+	}
+}
+
+// UpdateAfterFilter implements an attack updated_after filter
+// in the Filter function format
+func UpdateAfterFilter(d string) Filter {
+	return func(a AttackDetails) bool {
+		if d == "" {
+			return true
+		}
+		t, err := parseFilterTime(d)
+		// If parsing failed, don't filter
+		if err != nil {
+			return true
+		}
+
+		attackTime, _ := time.Parse(time.RFC1123, a.UpdatedAt)
+
+		return attackTime.After(t)
+	}
+}
+
+// URLPrefixFilter implements an attack url_prefix filter in the Filter
+// function format. Attacks with any target URL starting with the prefix match.
+func URLPrefixFilter(prefix string) Filter {
+	return func(a AttackDetails) bool {
+		if prefix == "" {
+			return true
+		}
+
+		for _, t := range attackTargets(a) {
+			if strings.HasPrefix(t.URL, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// URLRegexFilter implements an attack url_regex filter in the Filter function
+// format. Attacks with any target URL matching the regular expression match.
+func URLRegexFilter(expr string) Filter {
+	re, err := regexp.Compile(expr)
+	return func(a AttackDetails) bool {
+		// If parsing failed, don't filter
+		if expr == "" || err != nil {
+			return true
+		}
+
+		for _, t := range attackTargets(a) {
+			if re.MatchString(t.URL) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MethodFilter implements an attack method filter in the Filter function
+// format. Attacks with any target using the method match.
+func MethodFilter(method string) Filter {
+	return func(a AttackDetails) bool {
+		if method == "" {
+			return true
+		}
+
+		for _, t := range attackTargets(a) {
+			if strings.EqualFold(t.Method, method) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RateMinFilter implements an attack rate_min filter
+// in the Filter function format
+func RateMinFilter(rate string) Filter {
+	return func(a AttackDetails) bool {
+		min, err := strconv.Atoi(rate)
+		// If parsing failed, don't filter
+		if err != nil {
+			return true
+		}
+
+		return a.Params.Rate >= min
+	}
+}
+
+// RateMaxFilter implements an attack rate_max filter
+// in the Filter function format
+func RateMaxFilter(rate string) Filter {
+	return func(a AttackDetails) bool {
+		max, err := strconv.Atoi(rate)
+		// If parsing failed, don't filter
+		if err != nil {
+			return true
+		}
+
+		return a.Params.Rate <= max
+	}
+}
+
+// LabelFilter implements an attack labels filter in the Filter function
+// format. Attacks with labels matching the label selector match.
+func LabelFilter(s string) Filter {
+	selector, err := ParseLabelSelector(s)
+	return func(a AttackDetails) bool {
+		// If parsing failed, don't filter
+		if err != nil {
+			return true
+		}
+
+		return selector.Matches(a.Params.Labels)
+	}
+}
+
+// ValidateFilters checks the filters that are not ignored when invalid: the
+// url_regex, rate_min, rate_max and labels filters.
+func ValidateFilters(params FilterParams) error {
+	if expr, ok := params["url_regex"].(string); ok && expr != "" {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid url_regex filter: %s", err)
+		}
+	}
+
+	if s, ok := params["labels"].(string); ok {
+		if _, err := ParseLabelSelector(s); err != nil {
+			return fmt.Errorf("invalid labels filter: %s", err)
+		}
+	}
+
+	for _, key := range []string{"rate_min", "rate_max"} {
+		if rate, ok := params[key].(string); ok && rate != "" {
+			if _, err := strconv.Atoi(rate); err != nil {
+				return fmt.Errorf("invalid %s filter %q, want an integer", key, rate)
+			}
+		}
+	}
+
+	return nil
+}
+
+// attackTargets returns the static targets of an attack
+func attackTargets(a AttackDetails) []Target {
+	targets := append([]Target{}, a.Params.Targets...)
+	if a.Params.Target.URL != "" {
+		targets = append(targets, a.Params.Target)
+	}
+	return targets
+}
@@ -0,0 +1,45 @@
+package models
+
+// AttackErrorCategory as a string enum
+type AttackErrorCategory string
+
+const (
+	// AttackErrorCategoryParamValidation captures enum value "param_validation"
+	AttackErrorCategoryParamValidation AttackErrorCategory = "param_validation"
+
+	// AttackErrorCategoryTLSConfig captures enum value "tls_config"
+	AttackErrorCategoryTLSConfig AttackErrorCategory = "tls_config"
+
+	// AttackErrorCategoryResolve captures enum value "resolve_failure"
+	AttackErrorCategoryResolve AttackErrorCategory = "resolve_failure"
+
+	// AttackErrorCategoryEncode captures enum value "encode_failure"
+	AttackErrorCategoryEncode AttackErrorCategory = "encode_failure"
+
+	// AttackErrorCategoryInternal captures enum value "internal"
+	AttackErrorCategoryInternal AttackErrorCategory = "internal"
+)
+
+const (
+	// AttackCanceledByUser is set on attacks canceled through the API,
+	// unless the request names who canceled it
+	AttackCanceledByUser = "user"
+
+	// AttackCanceledByServer is set on attacks canceled by the server, e.g.
+	// on shutdown
+	AttackCanceledByServer = "server"
+)
+
+// AttackError captures why an attack failed
+type AttackError struct {
+	Category  AttackErrorCategory `json:"category"`
+	Message   string              `json:"message"`
+	Timestamp string              `json:"timestamp"`
+}
+
+// AttackCancellation captures who or what canceled an attack, and why
+type AttackCancellation struct {
+	By        string `json:"by"`
+	Reason    string `json:"reason,omitempty"`
+	Timestamp string `json:"timestamp"`
+}
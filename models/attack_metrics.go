@@ -0,0 +1,33 @@
+package models
+
+// AttackMetrics captures a snapshot of the metrics of a running attack,
+// aggregated over all the results received so far
+type AttackMetrics struct {
+	ID        string `json:"id"`
+	Latencies struct {
+		Total int64 `json:"total"`
+		Mean  int64 `json:"mean"`
+		Max   int64 `json:"max"`
+		P50th int64 `json:"50th"`
+		P95th int64 `json:"95th"`
+		P99th int64 `json:"99th"`
+	} `json:"latencies"`
+	// Buckets holds the cumulative latency histogram of the attack
+	Buckets     []LatencyBucket `json:"buckets"`
+	Earliest    string          `json:"earliest"`
+	Latest      string          `json:"latest"`
+	Requests    uint64          `json:"requests"`
+	Rate        float64         `json:"rate"`
+	Throughput  float64         `json:"throughput"`
+	Success     float64         `json:"success"`
+	StatusCodes map[string]int  `json:"status_codes"`
+	Errors      []string        `json:"errors"`
+}
+
+// LatencyBucket captures the number of requests with a latency less than or
+// equal to the bucket upper bound
+type LatencyBucket struct {
+	// UpperBound of the bucket in seconds
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
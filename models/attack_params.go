@@ -8,7 +8,11 @@ type AttackHeader struct {
 
 // AttackParams request parameters
 type AttackParams struct {
+	// Rate is the constant request rate per second. With a Ramp, it is the
+	// starting rate of linear and step ramps, and the mean rate of sine ramps.
 	Rate int `json:"rate,omitempty" binding:"required"`
+	// Ramp replaces the constant Rate with a rate profile
+	Ramp *Ramp `json:"ramp,omitempty"`
 
 	Connections int64 `json:"connections,omitempty"`
 	Workers     int64 `json:"workers,omitempty"`
@@ -29,8 +33,104 @@ type AttackParams struct {
 	Insecure  bool `json:"insecure,omitempty"`
 	Keepalive bool `json:"keepalive,omitempty"`
 
-	Target  Target         `json:"target,omitempty" binding:"required"`
-	Headers []AttackHeader `json:"headers,omitempty"`
+	// Target is a single static target, mutually exclusive with Targets
+	Target Target `json:"target,omitempty"`
+	// Targets is a list of targets that are hit in a round-robin fashion
+	Targets []Target `json:"targets,omitempty"`
+	// TargetFile is a vegeta target file, mutually exclusive with Target and Targets
+	TargetFile *TargetFile    `json:"target-file,omitempty"`
+	Headers    []AttackHeader `json:"headers,omitempty"`
+
+	// Assertions are evaluated against the attack metrics once the attack
+	// completes, e.g. "p99 < 300ms", "success >= 0.999" or "rate >= 95%"
+	Assertions []string `json:"assertions,omitempty"`
+
+	// Webhooks are URLs notified of the attack status transitions, on top of
+	// the webhooks set in the server config
+	Webhooks []string `json:"webhooks,omitempty" binding:"omitempty,dive,url"`
+
+	// Priority of the attack in the dispatcher queue. Higher priority attacks
+	// run first.
+	Priority int `json:"priority,omitempty"`
+	// User submitting the attack, used to share the dispatcher queue fairly
+	// between users
+	User string `json:"user,omitempty"`
+
+	// Labels are free-form key/value pairs identifying the attack, e.g. its
+	// team, service or environment, which attacks can be filtered by
+	Labels map[string]string `json:"labels,omitempty"`
+	// Description of the attack
+	Description string `json:"description,omitempty"`
+}
+
+// Redacted returns a copy of the params without the client TLS key and
+// certificate, the request bodies and the target file, which are not shared
+// outside of the server, e.g. with webhooks
+func (p AttackParams) Redacted() AttackParams {
+	p.Key = ""
+	p.Cert = ""
+	p.Body = ""
+	p.Target.Body = ""
+
+	targets := make([]Target, len(p.Targets))
+	for i, target := range p.Targets {
+		target.Body = ""
+		targets[i] = target
+	}
+	if p.Targets != nil {
+		p.Targets = targets
+	}
+
+	if p.TargetFile != nil {
+		p.TargetFile = &TargetFile{Format: p.TargetFile.Format}
+	}
+	return p
+}
+
+// RampType as a string enum
+type RampType string
+
+const (
+
+	// RampTypeLinear captures enum value "linear"
+	RampTypeLinear RampType = "linear"
+
+	// RampTypeStep captures enum value "step"
+	RampTypeStep RampType = "step"
+
+	// RampTypeSine captures enum value "sine"
+	RampTypeSine RampType = "sine"
+)
+
+// Ramp describes a request rate profile, starting from the attack Rate
+type Ramp struct {
+	// Type of the ramp, one of "linear", "step" or "sine"
+	Type RampType `json:"type,omitempty"`
+
+	// To is the rate reached at the end of linear ramps, and the maximum
+	// rate of step ramps
+	To int `json:"to,omitempty"`
+
+	// Step is the rate increment of step ramps
+	Step int `json:"step,omitempty"`
+	// Every is the interval between the increments of step ramps, e.g. "10s"
+	Every string `json:"every,omitempty"`
+
+	// Amplitude of sine ramps, lower than the mean Rate
+	Amplitude int `json:"amplitude,omitempty"`
+	// Period of sine ramps, e.g. "1m"
+	Period string `json:"period,omitempty"`
+	// Offset at which sine ramps start, one of "mean-up" (default), "peak",
+	// "mean-down" or "trough"
+	Offset string `json:"offset,omitempty"`
+}
+
+// TargetFile captures a vegeta target file, as read by the vegeta CLI
+type TargetFile struct {
+	// Format of the target file, either "http" or "json"
+	Format string `json:"format,omitempty"`
+	// Data is the base64 encoded target file content
+	Data string `json:"data,omitempty"`
 }
 
 // Target request target parameters
@@ -38,6 +138,11 @@ type Target struct {
 	Method string `json:"method,omitempty"`
 	URL    string `json:"URL,omitempty"`
 	Scheme string `json:"scheme,omitempty"`
+
+	// Headers are added to the attack level headers for this target only
+	Headers []AttackHeader `json:"headers,omitempty"`
+	// Body is a base64 encoded request body, overriding the attack level body
+	Body string `json:"body,omitempty"`
 }
 
 // AttackStatus as a string enum
@@ -64,4 +169,8 @@ const (
 // AttackCancel request body
 type AttackCancel struct {
 	Cancel bool `json:"cancel" binding:"required"`
+	// By names who canceled the attack, defaults to "user"
+	By string `json:"by,omitempty"`
+	// Reason the attack was canceled
+	Reason string `json:"reason,omitempty"`
 }
@@ -2,3 +2,10 @@ package models
 
 // AttackResponse with attacks UUID and AttackStatus
 type AttackResponse AttackInfo
+
+// AttackDeleteResponse lists the attacks deleted by a bulk delete, and the
+// attacks skipped, mostly as they are yet to end
+type AttackDeleteResponse struct {
+	Deleted []string `json:"deleted"`
+	Skipped []string `json:"skipped"`
+}
@@ -0,0 +1,313 @@
+package models
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// boltAttacks maps attack IDs to their metadata, stored as JSON
+	boltAttacks = []byte("attacks")
+	// boltResults maps attack IDs to their results
+	boltResults = []byte("results")
+	// boltCreatedIndex indexes attack IDs by creation time, with keys made of
+	// the big endian unix creation time followed by the attack ID
+	boltCreatedIndex = []byte("index_created")
+	// boltStatusIndex indexes attack IDs by status, with keys made of the
+	// status, a zero byte and the attack ID
+	boltStatusIndex = []byte("index_status")
+)
+
+// Bolt stores all Attack/Report information in an embedded database file, so
+// that attacks survive restarts without running Redis. Attacks are indexed by
+// creation time and status, and results are only read when getting a single
+// attack.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// NewBolt opens the database file at the path, creating it if needed
+func NewBolt(path string) (Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return Bolt{}, errors.Wrap(err, fmt.Sprintf("failed to open database file %s", path))
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltAttacks, boltResults, boltCreatedIndex, boltStatusIndex} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return Bolt{}, errors.Wrap(err, "failed to create buckets")
+	}
+
+	return Bolt{
+		db,
+	}, nil
+}
+
+// Close the database file
+func (b Bolt) Close() error {
+	return b.db.Close()
+}
+
+// Add attack details to the store, replacing the attack with the same ID
+func (b Bolt) Add(attack AttackDetails) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return b.put(tx, attack)
+	})
+}
+
+// GetAll returns the attacks matching the filters, without their results.
+// Attacks are read from the status index when filtering by status, and from
+// the creation time index otherwise, in creation order.
+func (b Bolt) GetAll(filterParams FilterParams) []AttackDetails {
+	attacks := make([]AttackDetails, 0)
+	filters := createFilterChain(filterParams)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltAttacks)
+
+		for _, id := range b.ids(tx, filterParams) {
+			v := bucket.Get([]byte(id))
+			if v == nil {
+				continue
+			}
+
+			var attack AttackDetails
+			if err := json.Unmarshal(v, &attack); err != nil {
+				continue
+			}
+			for _, filter := range filters {
+				if !filter(attack) {
+					goto skip
+				}
+			}
+			attacks = append(attacks, attack)
+		skip:
+		}
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	return attacks
+}
+
+// GetByID returns an attack along with its result
+func (b Bolt) GetByID(id string) (AttackDetails, error) {
+	var attack AttackDetails
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		if attack, err = b.get(tx, id); err != nil {
+			return err
+		}
+
+		// Values are only valid during the transaction
+		if result := tx.Bucket(boltResults).Get([]byte(id)); result != nil {
+			attack.Result = append([]byte{}, result...)
+		}
+		return nil
+	})
+
+	return attack, err
+}
+
+// GetInfoByID returns an attack without reading its result
+func (b Bolt) GetInfoByID(id string) (AttackDetails, error) {
+	var attack AttackDetails
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		attack, err = b.get(tx, id)
+		return err
+	})
+
+	return attack, err
+}
+
+// get the stored attack with the ID, without its result
+func (b Bolt) get(tx *bolt.Tx, id string) (AttackDetails, error) {
+	var attack AttackDetails
+
+	v := tx.Bucket(boltAttacks).Get([]byte(id))
+	if v == nil {
+		return attack, fmt.Errorf("attack with id %s not found", id)
+	}
+	err := json.Unmarshal(v, &attack)
+	return attack, err
+}
+
+// Update an attack in the store
+func (b Bolt) Update(id string, attack AttackDetails) error {
+	if attack.ID != id {
+		return fmt.Errorf("update ID %s and attack ID %s do not match", id, attack.ID)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(boltAttacks).Get([]byte(id)) == nil {
+			return fmt.Errorf("attack with id %s not found", id)
+		}
+		return b.put(tx, attack)
+	})
+}
+
+// Delete an attack by ID, along with its result
+func (b Bolt) Delete(id string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(boltAttacks).Get([]byte(id)) == nil {
+			return fmt.Errorf("attack with id %s not found", id)
+		}
+		if err := b.unindex(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Bucket(boltResults).Delete([]byte(id)); err != nil {
+			return err
+		}
+		return tx.Bucket(boltAttacks).Delete([]byte(id))
+	})
+}
+
+// put stores an attack and its result, and replaces its index entries
+func (b Bolt) put(tx *bolt.Tx, attack AttackDetails) error {
+	if err := b.unindex(tx, attack.ID); err != nil {
+		return err
+	}
+
+	// The result is stored separately, so that listing attacks only reads
+	// their metadata
+	if attack.Result != nil {
+		if err := tx.Bucket(boltResults).Put([]byte(attack.ID), attack.Result); err != nil {
+			return err
+		}
+		attack.ResultSize = int64(len(attack.Result))
+		attack.Result = nil
+	}
+
+	v, err := json.Marshal(attack)
+	if err != nil {
+		return err
+	}
+	if err = tx.Bucket(boltAttacks).Put([]byte(attack.ID), v); err != nil {
+		return err
+	}
+
+	if err = tx.Bucket(boltCreatedIndex).Put(createdIndexKey(attack), nil); err != nil {
+		return err
+	}
+	return tx.Bucket(boltStatusIndex).Put(statusIndexKey(attack.Status, attack.ID), nil)
+}
+
+// unindex removes the index entries of the stored attack with the ID, if any
+func (b Bolt) unindex(tx *bolt.Tx, id string) error {
+	v := tx.Bucket(boltAttacks).Get([]byte(id))
+	if v == nil {
+		return nil
+	}
+
+	var attack AttackDetails
+	if err := json.Unmarshal(v, &attack); err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(boltCreatedIndex).Delete(createdIndexKey(attack)); err != nil {
+		return err
+	}
+	return tx.Bucket(boltStatusIndex).Delete(statusIndexKey(attack.Status, attack.ID))
+}
+
+// ids returns the IDs of the attacks that may match the filters, using the
+// status index or the creation time index
+func (b Bolt) ids(tx *bolt.Tx, filterParams FilterParams) []string {
+	ids := make([]string, 0)
+
+	if status, ok := filterParams["status"].(string); ok && status != "" {
+		prefix := statusIndexKey(AttackStatus(status), "")
+		c := tx.Bucket(boltStatusIndex).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			ids = append(ids, string(k[len(prefix):]))
+		}
+		return ids
+	}
+
+	// Only the creation time range matching the filters is read. Attack
+	// creation times have a second precision, like the filters.
+	var from []byte
+	if after, ok := filterParams["created_after"].(string); ok {
+		if t, err := parseFilterTime(after); err == nil {
+			from = make([]byte, 8)
+			binary.BigEndian.PutUint64(from, uint64(t.Unix()+1))
+		}
+	}
+	to := int64(-1)
+	if before, ok := filterParams["created_before"].(string); ok {
+		if t, err := parseFilterTime(before); err == nil {
+			to = t.Unix()
+		}
+	}
+
+	c := tx.Bucket(boltCreatedIndex).Cursor()
+	k, _ := c.First()
+	if from != nil {
+		k, _ = c.Seek(from)
+	}
+	for ; k != nil; k, _ = c.Next() {
+		if to >= 0 && int64(binary.BigEndian.Uint64(k[:8])) >= to {
+			break
+		}
+		ids = append(ids, string(k[8:]))
+	}
+	return ids
+}
+
+// createdIndexKey returns the creation time index key of an attack
+func createdIndexKey(attack AttackDetails) []byte {
+	key := make([]byte, 8, 8+len(attack.ID))
+	binary.BigEndian.PutUint64(key, uint64(createdScore(attack)))
+	return append(key, attack.ID...)
+}
+
+// statusIndexKey returns the status index key of an attack
+func statusIndexKey(status AttackStatus, id string) []byte {
+	return []byte(string(status) + "\x00" + id)
+}
+
+// CancelInterrupted marks the scheduled and running attacks of the store as
+// canceled by the server. Attacks are interrupted when the server stops, so
+// those left in a persistent store by a previous run never end otherwise.
+func CancelInterrupted(store IAttackStore, reason string) ([]string, error) {
+	canceled := make([]string, 0)
+	now := time.Now().Format(time.RFC1123)
+
+	for _, status := range []AttackStatus{AttackResponseStatusScheduled, AttackResponseStatusRunning} {
+		for _, attack := range store.GetAll(FilterParams{"status": string(status)}) {
+			attack.Status = AttackResponseStatusCanceled
+			attack.Cancellation = &AttackCancellation{
+				By:        AttackCanceledByServer,
+				Reason:    reason,
+				Timestamp: now,
+			}
+			attack.UpdatedAt = now
+
+			if err := store.Update(attack.ID, attack); err != nil {
+				return canceled, errors.Wrap(err, fmt.Sprintf("failed to cancel interrupted attack %s", attack.ID))
+			}
+			canceled = append(canceled, attack.ID)
+		}
+	}
+
+	return canceled, nil
+}
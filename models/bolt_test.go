@@ -0,0 +1,158 @@
+package models
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestBolt(t *testing.T) (Bolt, string, func()) {
+	dir, err := ioutil.TempDir("", "vegeta-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "vegeta-server.db")
+
+	b, err := NewBolt(path)
+	if err != nil {
+		t.Fatalf("NewBolt() error = %v", err)
+	}
+
+	return b, path, func() {
+		_ = b.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestBolt_GetAll(t *testing.T) {
+	b, _, cleanup := newTestBolt(t)
+	defer cleanup()
+
+	attacks := []AttackDetails{
+		redisAttack("b", AttackResponseStatusCompleted, "Wed, 02 Jan 2019 02:00:00 UTC"),
+		redisAttack("a", AttackResponseStatusRunning, "Wed, 02 Jan 2019 03:00:00 UTC"),
+		redisAttack("c", AttackResponseStatusCompleted, "Wed, 02 Jan 2019 01:00:00 UTC"),
+	}
+	attacks[1].Params.Labels = map[string]string{"team": "payments"}
+	for _, a := range attacks {
+		if err := b.Add(a); err != nil {
+			t.Fatalf("Bolt.Add() error = %v", err)
+		}
+	}
+
+	// The running attack completes
+	attacks[1].Status = AttackResponseStatusCompleted
+	if err := b.Update("a", attacks[1]); err != nil {
+		t.Fatalf("Bolt.Update() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		filters FilterParams
+		want    []string
+	}{
+		{"All", FilterParams{}, []string{"c", "b", "a"}},
+		{"Status", FilterParams{"status": "completed"}, []string{"a", "b", "c"}},
+		{"Status mismatch", FilterParams{"status": "running"}, []string{}},
+		{"Filtered", FilterParams{"labels": "team=payments"}, []string{"a"}},
+		{"Created bad date", FilterParams{"created_after": "bad date"}, []string{"c", "b", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redisIDs(b.GetAll(tt.filters)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Bolt.GetAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBolt_Result(t *testing.T) {
+	b, path, cleanup := newTestBolt(t)
+	defer cleanup()
+
+	attack := redisAttack("1", AttackResponseStatusCompleted, "Wed, 02 Jan 2019 01:00:00 UTC")
+	attack.Result = []byte("result")
+	if err := b.Add(attack); err != nil {
+		t.Fatalf("Bolt.Add() error = %v", err)
+	}
+
+	all := b.GetAll(FilterParams{})
+	if len(all) != 1 || all[0].Result != nil || all[0].ResultLen() != 6 {
+		t.Errorf("Bolt.GetAll() = %v, want metadata only with the result size", all)
+	}
+
+	// Attacks survive reopening the database file
+	if err := b.Close(); err != nil {
+		t.Fatalf("Bolt.Close() error = %v", err)
+	}
+	reopened, err := NewBolt(path)
+	if err != nil {
+		t.Fatalf("NewBolt() error = %v", err)
+	}
+	defer reopened.Close() // nolint: errcheck
+
+	info, err := reopened.GetInfoByID("1")
+	if err != nil || info.Result != nil || info.ResultLen() != 6 {
+		t.Errorf("Bolt.GetInfoByID() = %v, %v, want metadata only with the result size", info, err)
+	}
+
+	got, err := reopened.GetByID("1")
+	if err != nil || !bytes.Equal(got.Result, attack.Result) {
+		t.Errorf("Bolt.GetByID() result = %s, %v, want %s", got.Result, err, attack.Result)
+	}
+}
+
+func TestBolt_Delete(t *testing.T) {
+	b, _, cleanup := newTestBolt(t)
+	defer cleanup()
+
+	attack := redisAttack("1", AttackResponseStatusCompleted, "")
+	attack.Result = []byte("result")
+	if err := b.Add(attack); err != nil {
+		t.Fatalf("Bolt.Add() error = %v", err)
+	}
+
+	if err := b.Delete("1"); err != nil {
+		t.Fatalf("Bolt.Delete() error = %v", err)
+	}
+	if _, err := b.GetByID("1"); err == nil {
+		t.Error("Bolt.GetByID() found the deleted attack")
+	}
+	if got := b.GetAll(FilterParams{"status": "completed"}); len(got) != 0 {
+		t.Errorf("Bolt.GetAll() = %v, want the deleted attack unindexed", got)
+	}
+	if err := b.Delete("1"); err == nil {
+		t.Error("Bolt.Delete() of a missing attack succeeded")
+	}
+	if err := b.Update("1", attack); err == nil {
+		t.Error("Bolt.Update() of a missing attack succeeded")
+	}
+}
+
+func TestCancelInterrupted(t *testing.T) {
+	tm := TaskMap{
+		"1": redisAttack("1", AttackResponseStatusRunning, ""),
+		"2": redisAttack("2", AttackResponseStatusScheduled, ""),
+		"3": redisAttack("3", AttackResponseStatusCompleted, ""),
+	}
+
+	canceled, err := CancelInterrupted(tm, "server restarted")
+	if err != nil {
+		t.Fatalf("CancelInterrupted() error = %v", err)
+	}
+	if len(canceled) != 2 {
+		t.Errorf("CancelInterrupted() = %v, want 2 attacks", canceled)
+	}
+
+	for _, id := range []string{"1", "2"} {
+		if a := tm[id]; a.Status != AttackResponseStatusCanceled || a.Cancellation == nil || a.Cancellation.By != AttackCanceledByServer {
+			t.Errorf("attack %s = %v, want canceled by the server", id, a.AttackInfo)
+		}
+	}
+	if tm["3"].Status != AttackResponseStatusCompleted {
+		t.Errorf("attack 3 status = %v, want completed", tm["3"].Status)
+	}
+}
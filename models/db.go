@@ -1,11 +1,20 @@
 package models
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
 )
 
 // IAttackStore captures all methods related to storing and retrieving attack details
@@ -13,10 +22,14 @@ type IAttackStore interface {
 	// Add item by its ID string
 	Add(AttackDetails) error
 
-	// GetAll items
+	// GetAll items. Stores may omit the results, which are then loaded with
+	// GetByID.
 	GetAll(filters FilterParams) []AttackDetails
-	// GetByID gets an item by its ID
+	// GetByID gets an item by its ID, along with its result
 	GetByID(string) (AttackDetails, error)
+	// GetInfoByID gets an item by its ID. Stores may omit the result, which
+	// is then loaded with GetByID.
+	GetInfoByID(string) (AttackDetails, error)
 
 	// Update multiple fields in an item
 	Update(string, AttackDetails) error
@@ -29,14 +42,43 @@ type IAttackStore interface {
 
 var mu sync.RWMutex
 
-// Redis stores all Attack/Report information in a redis database
+// Redis stores all Attack/Report information in a redis database. Attacks are
+// stored as JSON under namespaced keys, and indexed by creation time in a
+// sorted set and by status in a set per status, so that the database can be
+// shared with other tools. Results are stored under their own keys, and only
+// read when getting a single attack.
 type Redis struct {
 	connFn func() redis.Conn
+	// prefix namespaces all the keys of the store
+	prefix string
+	// ttl is the time ended attacks are kept for, zero to keep them forever
+	ttl time.Duration
+	// compress the results with gzip
+	compress bool
 }
 
-func NewRedis(f func() redis.Conn) Redis {
+// redisBatchSize is the number of attacks read at once with MGET, and the
+// number of keys scanned at once with SCAN
+const redisBatchSize = 100
+
+// attackStatuses lists the statuses indexed by the Redis store
+var attackStatuses = []AttackStatus{
+	AttackResponseStatusScheduled,
+	AttackResponseStatusRunning,
+	AttackResponseStatusCanceled,
+	AttackResponseStatusCompleted,
+	AttackResponseStatusFailed,
+}
+
+// NewRedis returns a Redis store, with all keys starting with the prefix.
+// Ended attacks expire after the ttl, unless it is zero. Results are
+// compressed with gzip if set.
+func NewRedis(f func() redis.Conn, prefix string, ttl time.Duration, compress bool) Redis {
 	return Redis{
 		f,
+		prefix,
+		ttl,
+		compress,
 	}
 }
 
@@ -44,17 +86,67 @@ func (r Redis) Add(attack AttackDetails) error {
 	conn := r.connFn()
 	defer conn.Close()
 
+	// The result is stored under its own key, so that listing attacks only
+	// reads their metadata
+	result := attack.Result
+	if result != nil {
+		attack.ResultSize = int64(len(result))
+		attack.Result = nil
+	}
+
 	v, err := json.Marshal(attack)
 	if err != nil {
 		return err
 	}
-	_, err = conn.Do("SET", attack.ID, v)
-	if err != nil {
-		return err
+
+	var expiry []interface{}
+	ended := attack.Status != AttackResponseStatusScheduled && attack.Status != AttackResponseStatusRunning
+	if r.ttl > 0 && ended {
+		expiry = []interface{}{"PX", int64(r.ttl / time.Millisecond)}
 	}
-	return nil
+
+	cmds := []redisCmd{
+		{"SET", append([]interface{}{r.attackKey(attack.ID), v}, expiry...)},
+		{"ZADD", []interface{}{r.indexKey(), createdScore(attack), attack.ID}},
+	}
+	if result != nil {
+		if r.compress {
+			if result, err = compressResult(result); err != nil {
+				return err
+			}
+		}
+		cmds = append(cmds, redisCmd{"SET", append([]interface{}{r.resultKey(attack.ID), result}, expiry...)})
+	} else if attack.ResultPath != "" && attack.Status == AttackResponseStatusCompleted {
+		// Results are spooled to files on the host that ran the attack, so
+		// they are copied once to the result key for the other hosts sharing
+		// the database
+		stored, err := redis.Bool(conn.Do("EXISTS", r.resultKey(attack.ID)))
+		if err != nil {
+			return err
+		}
+		if !stored {
+			if err := r.writeResult(conn, attack.ID, attack.ResultPath); err != nil {
+				return err
+			}
+			cmds = append(cmds, redisCmd{"RENAME", []interface{}{r.tempResultKey(attack.ID), r.resultKey(attack.ID)}})
+			if len(expiry) > 0 {
+				cmds = append(cmds, redisCmd{"PEXPIRE", []interface{}{r.resultKey(attack.ID), expiry[1]}})
+			}
+		}
+	}
+	for _, status := range attackStatuses {
+		if status != attack.Status {
+			cmds = append(cmds, redisCmd{"SREM", []interface{}{r.statusKey(status), attack.ID}})
+		}
+	}
+	cmds = append(cmds, redisCmd{"SADD", []interface{}{r.statusKey(attack.Status), attack.ID}})
+
+	return r.exec(conn, cmds)
 }
 
+// GetAll returns the attacks matching the filters, in creation order unless
+// filtered by status. IDs indexed for expired attacks are removed from the
+// indexes, and values that are not attacks are skipped.
 func (r Redis) GetAll(filterParams FilterParams) []AttackDetails {
 	attacks := make([]AttackDetails, 0)
 
@@ -62,36 +154,57 @@ func (r Redis) GetAll(filterParams FilterParams) []AttackDetails {
 	conn := r.connFn()
 	defer conn.Close()
 
-	res, err := conn.Do("KEYS", "*")
-	if err != nil {
-		return nil
+	// Only the attacks with the status are read when filtering by status
+	var ids []string
+	var err error
+	if status, ok := filterParams["status"].(string); ok && status != "" {
+		ids, err = redis.Strings(conn.Do("SMEMBERS", r.statusKey(AttackStatus(status))))
+	} else {
+		ids, err = redis.Strings(conn.Do("ZRANGE", r.indexKey(), 0, -1))
 	}
-	attackIDs, ok := res.([]interface{})
-	if !ok {
+	if err != nil {
 		return nil
 	}
 
-	for _, attackID := range attackIDs {
-		var attack AttackDetails
-
-		res, err := conn.Do("GET", attackID)
-		if err != nil {
-			return nil
+	expired := make([]string, 0)
+	for start := 0; start < len(ids); start += redisBatchSize {
+		batch := ids[start:]
+		if len(batch) > redisBatchSize {
+			batch = batch[:redisBatchSize]
 		}
 
-		err = json.Unmarshal(res.([]byte), &attack)
+		keys := make([]interface{}, 0, len(batch))
+		for _, id := range batch {
+			keys = append(keys, r.attackKey(id))
+		}
+		values, err := redis.ByteSlices(conn.Do("MGET", keys...))
 		if err != nil {
 			return nil
 		}
-		for _, filter := range filters {
-			if !filter(attack) {
-				goto skip
+
+		for i, v := range values {
+			if v == nil {
+				expired = append(expired, batch[i])
+				continue
+			}
+
+			attack, ok := decodeAttack(v, batch[i])
+			if !ok {
+				continue
+			}
+			for _, filter := range filters {
+				if !filter(attack) {
+					goto skip
+				}
 			}
+			attacks = append(attacks, attack)
+		skip:
 		}
-		attacks = append(attacks, attack)
-	skip:
 	}
 
+	// The indexes are cleaned up on a best effort basis
+	_ = r.exec(conn, r.unindexCmds(expired))
+
 	return attacks
 }
 
@@ -100,16 +213,43 @@ func (r Redis) GetByID(id string) (AttackDetails, error) {
 	conn := r.connFn()
 	defer conn.Close()
 
-	res, err := conn.Do("GET", id)
+	res, err := redis.ByteSlices(conn.Do("MGET", r.attackKey(id), r.resultKey(id)))
 	if err != nil {
 		return attack, err
 	}
+	if res[0] == nil {
+		return attack, fmt.Errorf("attack with id %s not found", id)
+	}
+
+	err = json.Unmarshal(res[0], &attack)
+	if err != nil {
+		return attack, err
+	}
+
+	// Only completed attacks have a result key. Attacks stored before results
+	// had their own keys have their result inlined instead.
+	if res[1] != nil {
+		attack.Result, err = decompressResult(res[1])
+	}
+
+	return attack, err
+}
+
+// GetInfoByID returns an attack without reading its result key
+func (r Redis) GetInfoByID(id string) (AttackDetails, error) {
+	var attack AttackDetails
+	conn := r.connFn()
+	defer conn.Close()
 
-	err = json.Unmarshal(res.([]byte), &attack)
+	v, err := redis.Bytes(conn.Do("GET", r.attackKey(id)))
+	if err == redis.ErrNil {
+		return attack, fmt.Errorf("attack with id %s not found", id)
+	}
 	if err != nil {
 		return attack, err
 	}
 
+	err = json.Unmarshal(v, &attack)
 	return attack, err
 }
 
@@ -124,11 +264,235 @@ func (r Redis) Delete(id string) error {
 	conn := r.connFn()
 	defer conn.Close()
 
-	_, err := conn.Do("DEL", id)
+	cmds := []redisCmd{{"DEL", []interface{}{r.attackKey(id), r.resultKey(id), r.tempResultKey(id)}}}
+	return r.exec(conn, append(cmds, r.unindexCmds([]string{id})...))
+}
+
+// Reindex adds the attacks stored under the key prefix to the indexes, e.g.
+// after the indexes were lost. Keys are scanned rather than listed at once,
+// so as not to block Redis.
+func (r Redis) Reindex() error {
+	conn := r.connFn()
+	defer conn.Close()
+
+	cursor := "0"
+	for {
+		res, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", r.attackKey("*"), "COUNT", redisBatchSize))
+		if err != nil {
+			return errors.Wrap(err, "failed to scan attack keys")
+		}
+		var keys []string
+		if _, err = redis.Scan(res, &cursor, &keys); err != nil {
+			return errors.Wrap(err, "failed to read scanned attack keys")
+		}
+
+		if len(keys) > 0 {
+			args := make([]interface{}, 0, len(keys))
+			for _, k := range keys {
+				args = append(args, k)
+			}
+			values, err := redis.ByteSlices(conn.Do("MGET", args...))
+			if err != nil {
+				return errors.Wrap(err, "failed to get scanned attacks")
+			}
+
+			cmds := make([]redisCmd, 0)
+			for i, v := range values {
+				attack, ok := decodeAttack(v, strings.TrimPrefix(keys[i], r.attackKey("")))
+				if !ok {
+					continue
+				}
+				cmds = append(cmds,
+					redisCmd{"ZADD", []interface{}{r.indexKey(), createdScore(attack), attack.ID}},
+					redisCmd{"SADD", []interface{}{r.statusKey(attack.Status), attack.ID}},
+				)
+			}
+			if err = r.exec(conn, cmds); err != nil {
+				return errors.Wrap(err, "failed to index scanned attacks")
+			}
+		}
+
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// writeResult streams the result spooled to the file at path to the temporary
+// result key of the attack, in chunks of redisChunkSize bytes. The key is
+// renamed once the result is complete, so that partial results are never read.
+func (r Redis) writeResult(conn redis.Conn, id string, path string) error {
+	f, err := os.Open(path)
 	if err != nil {
+		return errors.Wrap(err, "failed to open result")
+	}
+	defer f.Close() // nolint: errcheck
+
+	key := r.tempResultKey(id)
+	if _, err := conn.Do("DEL", key); err != nil {
 		return err
 	}
-	return nil
+
+	buf := bufio.NewWriterSize(redisAppender{conn, key}, redisChunkSize)
+	var w io.Writer = buf
+	var gz *gzip.Writer
+	if r.compress {
+		gz = gzip.NewWriter(buf)
+		w = gz
+	}
+
+	if _, err := io.Copy(w, f); err != nil {
+		return errors.Wrap(err, "failed to store result")
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return errors.Wrap(err, "failed to compress result")
+		}
+	}
+	return errors.Wrap(buf.Flush(), "failed to store result")
+}
+
+// redisChunkSize is the number of bytes of a result appended at once
+const redisChunkSize = 1 << 20
+
+// redisAppender appends all writes to a Redis key
+type redisAppender struct {
+	conn redis.Conn
+	key  string
+}
+
+func (a redisAppender) Write(p []byte) (int, error) {
+	if _, err := a.conn.Do("APPEND", a.key, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// unindexCmds returns the commands removing attack IDs from the indexes
+func (r Redis) unindexCmds(ids []string) []redisCmd {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, id)
+	}
+
+	cmds := []redisCmd{{"ZREM", append([]interface{}{r.indexKey()}, members...)}}
+	for _, status := range attackStatuses {
+		cmds = append(cmds, redisCmd{"SREM", append([]interface{}{r.statusKey(status)}, members...)})
+	}
+	return cmds
+}
+
+// redisCmd is a Redis command queued in a transaction
+type redisCmd struct {
+	name string
+	args []interface{}
+}
+
+// exec runs the commands in a transaction
+func (r Redis) exec(conn redis.Conn, cmds []redisCmd) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		if err := conn.Send(cmd.name, cmd.args...); err != nil {
+			return err
+		}
+	}
+
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+// attackKey returns the key of the attack with the ID
+func (r Redis) attackKey(id string) string {
+	return r.prefix + "attack:" + id
+}
+
+// resultKey returns the key of the result of the attack with the ID
+func (r Redis) resultKey(id string) string {
+	return r.prefix + "result:" + id
+}
+
+// tempResultKey returns the key the result of the attack with the ID is
+// written to before it is complete
+func (r Redis) tempResultKey(id string) string {
+	return r.resultKey(id) + ":tmp"
+}
+
+// indexKey returns the key of the sorted set of attack IDs, scored by
+// creation time
+func (r Redis) indexKey() string {
+	return r.prefix + "attacks"
+}
+
+// statusKey returns the key of the set of IDs of the attacks with the status
+func (r Redis) statusKey(status AttackStatus) string {
+	return r.prefix + "status:" + string(status)
+}
+
+// createdScore returns the creation time of an attack as a unix timestamp
+func createdScore(attack AttackDetails) int64 {
+	t, err := time.Parse(time.RFC1123, attack.CreatedAt)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// compressResult compresses a result with gzip
+func compressResult(result []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(result); err != nil {
+		return nil, errors.Wrap(err, "failed to compress result")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to compress result")
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressResult decompresses a result compressed with gzip, and returns
+// uncompressed results as is. Results are stored compressed or not depending
+// on the store config when they were added.
+func decompressResult(result []byte) ([]byte, error) {
+	if !bytes.HasPrefix(result, gzipMagic) {
+		return result, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(result))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress result")
+	}
+	defer r.Close() // nolint: errcheck
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress result")
+	}
+	return b, nil
+}
+
+// gzipMagic are the first bytes of gzip data, which neither gob nor JSON
+// encoded results start with
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decodeAttack decodes an attack stored as JSON, reporting whether the value
+// is the attack with the ID
+func decodeAttack(v []byte, id string) (AttackDetails, bool) {
+	var attack AttackDetails
+	if err := json.Unmarshal(v, &attack); err != nil {
+		return attack, false
+	}
+	return attack, attack.ID != "" && attack.ID == id
 }
 
 // TaskMap is a map of attack ID's to their AttackDetails
@@ -182,6 +546,18 @@ func (tm TaskMap) GetByID(id string) (AttackDetails, error) {
 	return attack, nil
 }
 
+// GetInfoByID returns an attack detail by ID, without its result
+func (tm TaskMap) GetInfoByID(id string) (AttackDetails, error) {
+	attack, err := tm.GetByID(id)
+	if err != nil || attack.Result == nil {
+		return attack, err
+	}
+
+	attack.ResultSize = int64(len(attack.Result))
+	attack.Result = nil
+	return attack, nil
+}
+
 // Update an attack detail in the store
 func (tm TaskMap) Update(id string, attack AttackDetails) error {
 	if attack.ID != id {
@@ -239,5 +615,53 @@ func createFilterChain(params FilterParams) []Filter {
 			CreationAfterFilter(createdAfter.(string)),
 		)
 	}
+	if updatedBefore, ok := params["updated_before"]; ok {
+		filters = append(
+			filters,
+			UpdateBeforeFilter(updatedBefore.(string)),
+		)
+	}
+	if updatedAfter, ok := params["updated_after"]; ok {
+		filters = append(
+			filters,
+			UpdateAfterFilter(updatedAfter.(string)),
+		)
+	}
+	if prefix, ok := params["url_prefix"]; ok {
+		filters = append(
+			filters,
+			URLPrefixFilter(prefix.(string)),
+		)
+	}
+	if expr, ok := params["url_regex"]; ok {
+		filters = append(
+			filters,
+			URLRegexFilter(expr.(string)),
+		)
+	}
+	if method, ok := params["method"]; ok {
+		filters = append(
+			filters,
+			MethodFilter(method.(string)),
+		)
+	}
+	if rateMin, ok := params["rate_min"]; ok {
+		filters = append(
+			filters,
+			RateMinFilter(rateMin.(string)),
+		)
+	}
+	if rateMax, ok := params["rate_max"]; ok {
+		filters = append(
+			filters,
+			RateMaxFilter(rateMax.(string)),
+		)
+	}
+	if labels, ok := params["labels"]; ok {
+		filters = append(
+			filters,
+			LabelFilter(labels.(string)),
+		)
+	}
 	return filters
 }
@@ -77,6 +77,10 @@ func TestTaskMap_GetAll(t *testing.T) {
 	tests = append(tests, dataStatus()...)
 	tests = append(tests, dataBefore()...)
 	tests = append(tests, dataAfter()...)
+	tests = append(tests, dataUpdated()...)
+	tests = append(tests, dataTarget()...)
+	tests = append(tests, dataRate()...)
+	tests = append(tests, dataLabels()...)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -336,6 +340,185 @@ func dataAfter() []testAll {
 	return t
 }
 
+func dataUpdated() []testAll {
+	t := make([]testAll, 0)
+
+	attack := AttackDetails{
+		AttackInfo: AttackInfo{
+			ID:        "1",
+			UpdatedAt: "Wed, 02 Jan 2019 01:00:00 UTC",
+		},
+	}
+
+	before := testAll{
+		name: "OK - With Updated_Before filter match",
+		tm:   TaskMap{"1": attack},
+		args: argsAll{
+			filterParams: FilterParams{
+				"updated_before": "2019-01-03 00:00:00",
+			},
+		},
+		want: []AttackDetails{attack},
+	}
+
+	after := testAll{
+		name: "OK - With Updated_After filter mismatch",
+		tm:   TaskMap{"1": attack},
+		args: argsAll{
+			filterParams: FilterParams{
+				"updated_after": "2019-01-03 00:00:00",
+			},
+		},
+		want: []AttackDetails{},
+	}
+	t = append(t, before, after)
+
+	return t
+}
+
+func dataTarget() []testAll {
+	t := make([]testAll, 0)
+
+	attack := AttackDetails{
+		AttackInfo: AttackInfo{
+			ID: "1",
+			Params: AttackParams{
+				Targets: []Target{
+					{Method: "GET", URL: "https://api.example.com/users"},
+					{Method: "POST", URL: "https://api.example.com/orders/42"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filters FilterParams
+		match   bool
+	}{
+		{"OK - With URL_Prefix filter match", FilterParams{"url_prefix": "https://api.example.com/orders"}, true},
+		{"OK - With URL_Prefix filter mismatch", FilterParams{"url_prefix": "http://"}, false},
+		{"OK - With URL_Regex filter match", FilterParams{"url_regex": `/orders/\d+$`}, true},
+		{"OK - With URL_Regex filter mismatch", FilterParams{"url_regex": `/products`}, false},
+		{"OK - With URL_Regex filter failed", FilterParams{"url_regex": `(`}, true},
+		{"OK - With Method filter match", FilterParams{"method": "post"}, true},
+		{"OK - With Method filter mismatch", FilterParams{"method": "DELETE"}, false},
+		{"OK - With Method filter empty", FilterParams{"method": ""}, true},
+	}
+	for _, tt := range tests {
+		want := []AttackDetails{}
+		if tt.match {
+			want = append(want, attack)
+		}
+		t = append(t, testAll{
+			name: tt.name,
+			tm:   TaskMap{"1": attack},
+			args: argsAll{filterParams: tt.filters},
+			want: want,
+		})
+	}
+
+	return t
+}
+
+func dataRate() []testAll {
+	t := make([]testAll, 0)
+
+	attack := AttackDetails{
+		AttackInfo: AttackInfo{
+			ID:     "1",
+			Params: AttackParams{Rate: 50},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filters FilterParams
+		match   bool
+	}{
+		{"OK - With Rate_Min filter match", FilterParams{"rate_min": "50"}, true},
+		{"OK - With Rate_Min filter mismatch", FilterParams{"rate_min": "51"}, false},
+		{"OK - With Rate_Max filter match", FilterParams{"rate_max": "50"}, true},
+		{"OK - With Rate_Max filter mismatch", FilterParams{"rate_max": "49"}, false},
+		{"OK - With Rate range filter match", FilterParams{"rate_min": "10", "rate_max": "100"}, true},
+		{"OK - With Rate_Min filter failed", FilterParams{"rate_min": "fast"}, true},
+	}
+	for _, tt := range tests {
+		want := []AttackDetails{}
+		if tt.match {
+			want = append(want, attack)
+		}
+		t = append(t, testAll{
+			name: tt.name,
+			tm:   TaskMap{"1": attack},
+			args: argsAll{filterParams: tt.filters},
+			want: want,
+		})
+	}
+
+	return t
+}
+
+func dataLabels() []testAll {
+	t := make([]testAll, 0)
+
+	attack := AttackDetails{
+		AttackInfo: AttackInfo{
+			ID: "1",
+			Params: AttackParams{
+				Labels: map[string]string{"team": "payments", "env": "staging"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filters FilterParams
+		match   bool
+	}{
+		{"OK - With Labels filter match", FilterParams{"labels": "team=payments,env!=prod"}, true},
+		{"OK - With Labels filter mismatch", FilterParams{"labels": "team=search"}, false},
+		{"OK - With Labels filter empty", FilterParams{"labels": ""}, true},
+		{"OK - With Labels filter failed", FilterParams{"labels": "=payments"}, true},
+	}
+	for _, tt := range tests {
+		want := []AttackDetails{}
+		if tt.match {
+			want = append(want, attack)
+		}
+		t = append(t, testAll{
+			name: tt.name,
+			tm:   TaskMap{"1": attack},
+			args: argsAll{filterParams: tt.filters},
+			want: want,
+		})
+	}
+
+	return t
+}
+
+func TestValidateFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters FilterParams
+		wantErr bool
+	}{
+		{"OK", FilterParams{"url_regex": "^https://", "rate_min": "1", "rate_max": "10"}, false},
+		{"OK - Empty", FilterParams{"url_regex": "", "rate_min": "", "rate_max": ""}, false},
+		{"Bad URL_Regex", FilterParams{"url_regex": "("}, true},
+		{"Bad Rate_Min", FilterParams{"rate_min": "fast"}, true},
+		{"Bad Rate_Max", FilterParams{"rate_max": "1.5"}, true},
+		{"Bad Labels", FilterParams{"labels": "team=payments,"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFilters(tt.filters); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFilters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestTaskMap_GetByID(t *testing.T) {
 	type args struct {
 		id string
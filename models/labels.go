@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// labelSeparators are the characters reserved by the label selector syntax,
+// which label keys and values cannot contain
+const labelSeparators = ",=!"
+
+// ValidateLabels checks that the label keys are set, and that the label keys
+// and values can be matched by a label selector
+func ValidateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if strings.TrimSpace(k) == "" {
+			return fmt.Errorf("invalid label %q=%q, the key must be set", k, v)
+		}
+		if strings.ContainsAny(k, labelSeparators) || strings.ContainsAny(v, labelSeparators) {
+			return fmt.Errorf("invalid label %q=%q, keys and values cannot contain any of %q", k, v, labelSeparators)
+		}
+		if k != strings.TrimSpace(k) || v != strings.TrimSpace(v) {
+			return fmt.Errorf("invalid label %q=%q, keys and values cannot start or end with spaces", k, v)
+		}
+	}
+	return nil
+}
+
+// FormatLabels returns the labels as a comma separated list of key=value
+// pairs, sorted by key
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// labelOperator as a string enum
+type labelOperator string
+
+const (
+	labelEquals    labelOperator = "="
+	labelNotEquals labelOperator = "!="
+	labelExists    labelOperator = "exists"
+	labelNotExists labelOperator = "!exists"
+)
+
+// labelRequirement is a single comma separated term of a label selector
+type labelRequirement struct {
+	key      string
+	operator labelOperator
+	value    string
+}
+
+// LabelSelector matches attacks by their labels. All of its requirements
+// must hold for the labels to match.
+type LabelSelector []labelRequirement
+
+// ParseLabelSelector parses a comma separated list of label requirements, e.g.
+// "team=payments,env!=prod,ticket,!canary". "key=value" (or "key==value")
+// requires the label to be set to the value, "key!=value" requires it to be
+// unset or set to another value, "key" requires it to be set, and "!key"
+// requires it to be unset.
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	selector := make(LabelSelector, 0)
+	if strings.TrimSpace(s) == "" {
+		return selector, nil
+	}
+
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+
+		var r labelRequirement
+		switch {
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			r = labelRequirement{parts[0], labelNotEquals, parts[1]}
+		case strings.Contains(term, "=="):
+			parts := strings.SplitN(term, "==", 2)
+			r = labelRequirement{parts[0], labelEquals, parts[1]}
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(term, "=", 2)
+			r = labelRequirement{parts[0], labelEquals, parts[1]}
+		case strings.HasPrefix(term, "!"):
+			r = labelRequirement{term[1:], labelNotExists, ""}
+		default:
+			r = labelRequirement{term, labelExists, ""}
+		}
+
+		r.key, r.value = strings.TrimSpace(r.key), strings.TrimSpace(r.value)
+		if r.key == "" || strings.ContainsAny(r.key, labelSeparators) || strings.ContainsAny(r.value, labelSeparators) {
+			return nil, fmt.Errorf("invalid label selector term %q", term)
+		}
+		selector = append(selector, r)
+	}
+
+	return selector, nil
+}
+
+// Matches reports whether the labels match all of the selector requirements
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		v, ok := labels[r.key]
+
+		var holds bool
+		switch r.operator {
+		case labelEquals:
+			holds = ok && v == r.value
+		case labelNotEquals:
+			holds = !ok || v != r.value
+		case labelExists:
+			holds = ok
+		case labelNotExists:
+			holds = !ok
+		}
+		if !holds {
+			return false
+		}
+	}
+	return true
+}
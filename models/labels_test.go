@@ -0,0 +1,78 @@
+package models
+
+import "testing"
+
+func TestValidateLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{"OK", map[string]string{"team": "payments", "sha": "4f2c1e9"}, false},
+		{"OK - Empty value", map[string]string{"canary": ""}, false},
+		{"OK - None", nil, false},
+		{"Empty key", map[string]string{"": "payments"}, true},
+		{"Separator in key", map[string]string{"team,env": "payments"}, true},
+		{"Separator in value", map[string]string{"env": "!prod"}, true},
+		{"Spaces", map[string]string{"team": " payments"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateLabels(tt.labels); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	got := FormatLabels(map[string]string{"team": "payments", "env": "staging"})
+	if want := "env=staging,team=payments"; got != want {
+		t.Errorf("FormatLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestLabelSelector_Matches(t *testing.T) {
+	labels := map[string]string{
+		"team": "payments",
+		"env":  "staging",
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "Empty", selector: "", want: true},
+		{name: "Equals", selector: "team=payments", want: true},
+		{name: "Double Equals", selector: "team==payments", want: true},
+		{name: "Equals mismatch", selector: "team=search", want: false},
+		{name: "Not Equals", selector: "env!=prod", want: true},
+		{name: "Not Equals mismatch", selector: "env!=staging", want: false},
+		{name: "Not Equals unset", selector: "ticket!=OPS-1", want: true},
+		{name: "Exists", selector: "team", want: true},
+		{name: "Exists mismatch", selector: "ticket", want: false},
+		{name: "Not Exists", selector: "!ticket", want: true},
+		{name: "Not Exists mismatch", selector: "!env", want: false},
+		{name: "All", selector: "team=payments, env!=prod,!ticket", want: true},
+		{name: "All mismatch", selector: "team=payments,env=prod", want: false},
+		{name: "Bad empty key", selector: "=payments", wantErr: true},
+		{name: "Bad empty term", selector: "team=payments,", wantErr: true},
+		{name: "Bad value", selector: "env=prod=eu", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseLabelSelector(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLabelSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := s.Matches(labels); got != tt.want {
+				t.Errorf("LabelSelector.Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
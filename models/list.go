@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListSortField as a string enum
+type ListSortField string
+
+const (
+	// ListSortCreatedAt captures enum value "created_at"
+	ListSortCreatedAt ListSortField = "created_at"
+
+	// ListSortUpdatedAt captures enum value "updated_at"
+	ListSortUpdatedAt ListSortField = "updated_at"
+)
+
+// ListOrder as a string enum
+type ListOrder string
+
+const (
+	// ListOrderAsc captures enum value "asc"
+	ListOrderAsc ListOrder = "asc"
+
+	// ListOrderDesc captures enum value "desc"
+	ListOrderDesc ListOrder = "desc"
+)
+
+// ListParams captures the sorting and pagination of an attack list
+type ListParams struct {
+	// Sort is the attack timestamp to sort by, created_at by default
+	Sort ListSortField
+	// Order is the sort order, newest first by default
+	Order ListOrder
+	// Limit is the maximum number of attacks listed, zero for no limit
+	Limit int
+	// Cursor resumes the list after the last attack of the previous page
+	Cursor string
+}
+
+// AttackList captures a page of attacks
+type AttackList struct {
+	Attacks []*AttackResponse
+	// Total is the number of attacks matching the filters, over all pages
+	Total int
+	// NextCursor resumes the list on the next page, empty on the last page
+	NextCursor string
+}
+
+// Validate checks the list params and sets the defaults
+func (p *ListParams) Validate() error {
+	switch p.Sort {
+	case "":
+		p.Sort = ListSortCreatedAt
+	case ListSortCreatedAt, ListSortUpdatedAt:
+	default:
+		return fmt.Errorf("invalid sort %q, want created_at or updated_at", p.Sort)
+	}
+
+	switch p.Order {
+	case "":
+		p.Order = ListOrderDesc
+	case ListOrderAsc, ListOrderDesc:
+	default:
+		return fmt.Errorf("invalid order %q, want asc or desc", p.Order)
+	}
+
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit %d", p.Limit)
+	}
+
+	if p.Cursor != "" {
+		if _, _, err := decodeCursor(p.Cursor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Paginate sorts the attacks by the list params, and returns the page after
+// the cursor, along with the cursor of the next page. Attacks with the same
+// timestamp are sorted by ID, so that pages neither skip nor repeat attacks.
+func Paginate(attacks []AttackDetails, p ListParams) ([]AttackDetails, string, error) {
+	if err := p.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	type entry struct {
+		key    time.Time
+		attack AttackDetails
+	}
+	entries := make([]entry, 0, len(attacks))
+	for _, a := range attacks {
+		entries = append(entries, entry{sortKey(a, p.Sort), a})
+	}
+
+	before := func(t1 time.Time, id1 string, t2 time.Time, id2 string) bool {
+		if !t1.Equal(t2) {
+			return t1.Before(t2) == (p.Order == ListOrderAsc)
+		}
+		return (id1 < id2) == (p.Order == ListOrderAsc)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return before(entries[i].key, entries[i].attack.ID, entries[j].key, entries[j].attack.ID)
+	})
+
+	start := 0
+	if p.Cursor != "" {
+		t, id, _ := decodeCursor(p.Cursor)
+		start = sort.Search(len(entries), func(i int) bool {
+			return before(t, id, entries[i].key, entries[i].attack.ID)
+		})
+	}
+
+	end := len(entries)
+	if p.Limit > 0 && start+p.Limit < end {
+		end = start + p.Limit
+	}
+
+	page := make([]AttackDetails, 0, end-start)
+	for _, e := range entries[start:end] {
+		page = append(page, e.attack)
+	}
+
+	next := ""
+	if end < len(entries) && end > start {
+		last := entries[end-1]
+		next = encodeCursor(last.key, last.attack.ID)
+	}
+
+	return page, next, nil
+}
+
+func sortKey(a AttackDetails, field ListSortField) time.Time {
+	ts := a.CreatedAt
+	if field == ListSortUpdatedAt {
+		ts = a.UpdatedAt
+	}
+	t, _ := time.Parse(time.RFC1123, ts)
+	return t
+}
+
+// encodeCursor returns an opaque cursor for the attack timestamp and ID
+func encodeCursor(t time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d|%s", t.Unix(), id)))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	return time.Unix(sec, 0), parts[1], nil
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func listIDs(attacks []AttackDetails) []string {
+	ids := make([]string, 0, len(attacks))
+	for _, a := range attacks {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func TestPaginate(t *testing.T) {
+	attacks := []AttackDetails{
+		{AttackInfo: AttackInfo{ID: "b", CreatedAt: "Wed, 02 Jan 2019 01:00:00 UTC", UpdatedAt: "Wed, 02 Jan 2019 04:00:00 UTC"}},
+		{AttackInfo: AttackInfo{ID: "c", CreatedAt: "Wed, 02 Jan 2019 03:00:00 UTC", UpdatedAt: "Wed, 02 Jan 2019 03:00:00 UTC"}},
+		{AttackInfo: AttackInfo{ID: "a", CreatedAt: "Wed, 02 Jan 2019 01:00:00 UTC", UpdatedAt: "Wed, 02 Jan 2019 05:00:00 UTC"}},
+		{AttackInfo: AttackInfo{ID: "d", CreatedAt: "Wed, 02 Jan 2019 02:00:00 UTC", UpdatedAt: "Wed, 02 Jan 2019 02:00:00 UTC"}},
+	}
+
+	tests := []struct {
+		name    string
+		params  ListParams
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "Default Newest First",
+			params: ListParams{},
+			want:   []string{"c", "d", "b", "a"},
+		},
+		{
+			name:   "Created Ascending",
+			params: ListParams{Sort: ListSortCreatedAt, Order: ListOrderAsc},
+			want:   []string{"a", "b", "d", "c"},
+		},
+		{
+			name:   "Updated Descending",
+			params: ListParams{Sort: ListSortUpdatedAt, Order: ListOrderDesc},
+			want:   []string{"a", "b", "c", "d"},
+		},
+		{
+			name:   "Limit",
+			params: ListParams{Limit: 2},
+			want:   []string{"c", "d"},
+		},
+		{
+			name:    "Bad Sort",
+			params:  ListParams{Sort: "rate"},
+			wantErr: true,
+		},
+		{
+			name:    "Bad Order",
+			params:  ListParams{Order: "up"},
+			wantErr: true,
+		},
+		{
+			name:    "Bad Limit",
+			params:  ListParams{Limit: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Bad Cursor",
+			params:  ListParams{Cursor: "!!!"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := Paginate(attacks, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Paginate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if ids := listIDs(got); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Paginate() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginate_Cursor(t *testing.T) {
+	attacks := []AttackDetails{
+		{AttackInfo: AttackInfo{ID: "a", CreatedAt: "Wed, 02 Jan 2019 01:00:00 UTC"}},
+		{AttackInfo: AttackInfo{ID: "b", CreatedAt: "Wed, 02 Jan 2019 01:00:00 UTC"}},
+		{AttackInfo: AttackInfo{ID: "c", CreatedAt: "Wed, 02 Jan 2019 02:00:00 UTC"}},
+		{AttackInfo: AttackInfo{ID: "d", CreatedAt: "Wed, 02 Jan 2019 03:00:00 UTC"}},
+		{AttackInfo: AttackInfo{ID: "e", CreatedAt: "Wed, 02 Jan 2019 03:00:00 UTC"}},
+	}
+
+	got := make([]string, 0)
+	params := ListParams{Order: ListOrderAsc, Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > len(attacks) {
+			t.Fatal("Paginate() never reached the last page")
+		}
+
+		page, next, err := Paginate(attacks, params)
+		if err != nil {
+			t.Fatalf("Paginate() error = %v", err)
+		}
+		got = append(got, listIDs(page)...)
+
+		if next == "" {
+			break
+		}
+		params.Cursor = next
+	}
+
+	want := []string{"a", "b", "c", "d", "e"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Paginate() pages = %v, want %v", got, want)
+	}
+}
@@ -75,6 +75,27 @@ func (_m *IAttackStore) GetByID(_a0 string) (models.AttackDetails, error) {
 	return r0, r1
 }
 
+// GetInfoByID provides a mock function with given fields: _a0
+func (_m *IAttackStore) GetInfoByID(_a0 string) (models.AttackDetails, error) {
+	ret := _m.Called(_a0)
+
+	var r0 models.AttackDetails
+	if rf, ok := ret.Get(0).(func(string) models.AttackDetails); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(models.AttackDetails)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: _a0, _a1
 func (_m *IAttackStore) Update(_a0 string, _a1 models.AttackDetails) error {
 	ret := _m.Called(_a0, _a1)
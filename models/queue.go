@@ -0,0 +1,22 @@
+package models
+
+// QueueEntry captures an attack waiting in the dispatcher queue
+type QueueEntry struct {
+	ID string `json:"id"`
+	// Position is the 1-based position of the attack in the queue
+	Position  int    `json:"position"`
+	Priority  int    `json:"priority"`
+	User      string `json:"user,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// QueueResponse captures the state of the dispatcher queue
+type QueueResponse struct {
+	// MaxConcurrent is the maximum number of attacks running at once, zero
+	// if unlimited
+	MaxConcurrent int  `json:"max_concurrent"`
+	Fairness      bool `json:"fairness"`
+	// Running is the number of attacks currently running
+	Running int          `json:"running"`
+	Queued  []QueueEntry `json:"queued"`
+}
@@ -0,0 +1,469 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// fakeRedis is an in-memory redis.Conn implementing the commands used by the
+// Redis store
+type fakeRedis struct {
+	strings map[string][]byte
+	zsets   map[string]map[string]int64
+	sets    map[string]map[string]struct{}
+	ttls    map[string]int64
+	queued  [][]interface{}
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		make(map[string][]byte),
+		make(map[string]map[string]int64),
+		make(map[string]map[string]struct{}),
+		make(map[string]int64),
+		nil,
+	}
+}
+
+func (f *fakeRedis) Close() error { return nil }
+func (f *fakeRedis) Err() error   { return nil }
+func (f *fakeRedis) Flush() error { return nil }
+
+func (f *fakeRedis) Receive() (interface{}, error) {
+	return nil, fmt.Errorf("receive not supported")
+}
+
+func (f *fakeRedis) Send(cmd string, args ...interface{}) error {
+	f.queued = append(f.queued, append([]interface{}{cmd}, args...))
+	return nil
+}
+
+func (f *fakeRedis) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if cmd != "EXEC" {
+		return f.do(cmd, args...)
+	}
+
+	queued := f.queued
+	f.queued = nil
+
+	replies := make([]interface{}, 0, len(queued))
+	for _, q := range queued[1:] {
+		reply, err := f.do(q[0].(string), q[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
+
+func (f *fakeRedis) do(cmd string, args ...interface{}) (interface{}, error) {
+	s := make([]string, 0, len(args))
+	for _, a := range args {
+		if b, ok := a.([]byte); ok {
+			s = append(s, string(b))
+			continue
+		}
+		s = append(s, fmt.Sprint(a))
+	}
+
+	switch cmd {
+	case "SET":
+		f.strings[s[0]] = []byte(s[1])
+		delete(f.ttls, s[0])
+		if len(s) == 4 && s[2] == "PX" {
+			f.ttls[s[0]] = args[3].(int64)
+		}
+		return "OK", nil
+	case "GET":
+		if v, ok := f.strings[s[0]]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "MGET":
+		values := make([]interface{}, 0, len(s))
+		for _, k := range s {
+			if v, ok := f.strings[k]; ok {
+				values = append(values, v)
+				continue
+			}
+			values = append(values, nil)
+		}
+		return values, nil
+	case "APPEND":
+		f.strings[s[0]] = append(f.strings[s[0]], s[1]...)
+		return int64(len(f.strings[s[0]])), nil
+	case "EXISTS":
+		if _, ok := f.strings[s[0]]; ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "RENAME":
+		v, ok := f.strings[s[0]]
+		if !ok {
+			return nil, redis.Error("ERR no such key")
+		}
+		delete(f.strings, s[0])
+		f.strings[s[1]] = v
+		return "OK", nil
+	case "PEXPIRE":
+		f.ttls[s[0]] = args[1].(int64)
+		return int64(1), nil
+	case "DEL":
+		for _, k := range s {
+			delete(f.strings, k)
+		}
+		return int64(1), nil
+	case "ZADD":
+		if f.zsets[s[0]] == nil {
+			f.zsets[s[0]] = make(map[string]int64)
+		}
+		f.zsets[s[0]][s[2]] = args[1].(int64)
+		return int64(1), nil
+	case "ZREM":
+		for _, m := range s[1:] {
+			delete(f.zsets[s[0]], m)
+		}
+		return int64(1), nil
+	case "ZRANGE":
+		members := make([]string, 0)
+		for m := range f.zsets[s[0]] {
+			members = append(members, m)
+		}
+		sort.Slice(members, func(i, j int) bool {
+			zi, zj := f.zsets[s[0]][members[i]], f.zsets[s[0]][members[j]]
+			if zi != zj {
+				return zi < zj
+			}
+			return members[i] < members[j]
+		})
+		return bulk(members), nil
+	case "SADD":
+		if f.sets[s[0]] == nil {
+			f.sets[s[0]] = make(map[string]struct{})
+		}
+		f.sets[s[0]][s[1]] = struct{}{}
+		return int64(1), nil
+	case "SREM":
+		for _, m := range s[1:] {
+			delete(f.sets[s[0]], m)
+		}
+		return int64(1), nil
+	case "SMEMBERS":
+		members := make([]string, 0)
+		for m := range f.sets[s[0]] {
+			members = append(members, m)
+		}
+		sort.Strings(members)
+		return bulk(members), nil
+	case "SCAN":
+		keys := make([]string, 0)
+		for k := range f.strings {
+			if ok, _ := path.Match(s[2], k); ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		return []interface{}{[]byte("0"), bulk(keys)}, nil
+	}
+	return nil, fmt.Errorf("command %s not supported", cmd)
+}
+
+func bulk(s []string) []interface{} {
+	values := make([]interface{}, 0, len(s))
+	for _, v := range s {
+		values = append(values, []byte(v))
+	}
+	return values
+}
+
+func newTestRedis(f *fakeRedis, ttl time.Duration) Redis {
+	return NewRedis(func() redis.Conn { return f }, "vegeta:", ttl, false)
+}
+
+func redisAttack(id string, status AttackStatus, createdAt string) AttackDetails {
+	return AttackDetails{
+		AttackInfo: AttackInfo{
+			ID:        id,
+			Status:    status,
+			CreatedAt: createdAt,
+		},
+	}
+}
+
+func redisIDs(attacks []AttackDetails) []string {
+	ids := make([]string, 0, len(attacks))
+	for _, a := range attacks {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func TestRedis_Add(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(f, time.Minute)
+
+	attack := redisAttack("1", AttackResponseStatusRunning, "Wed, 02 Jan 2019 01:00:00 UTC")
+	if err := r.Add(attack); err != nil {
+		t.Fatalf("Redis.Add() error = %v", err)
+	}
+	attack.Status = AttackResponseStatusCompleted
+	if err := r.Update("1", attack); err != nil {
+		t.Fatalf("Redis.Update() error = %v", err)
+	}
+
+	if _, ok := f.strings["vegeta:attack:1"]; !ok {
+		t.Error("Redis.Add() did not store the attack under the prefixed key")
+	}
+	if _, ok := f.ttls["vegeta:attack:1"]; !ok {
+		t.Error("Redis.Add() did not expire the ended attack")
+	}
+	if score, ok := f.zsets["vegeta:attacks"]["1"]; !ok || score != 1546390800 {
+		t.Errorf("Redis.Add() indexed creation time = %v, %v, want 1546390800", score, ok)
+	}
+	if _, ok := f.sets["vegeta:status:completed"]["1"]; !ok {
+		t.Error("Redis.Add() did not index the attack status")
+	}
+	if _, ok := f.sets["vegeta:status:running"]["1"]; ok {
+		t.Error("Redis.Add() did not unindex the previous attack status")
+	}
+}
+
+func TestRedis_GetAll(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(f, 0)
+
+	attacks := []AttackDetails{
+		redisAttack("b", AttackResponseStatusCompleted, "Wed, 02 Jan 2019 02:00:00 UTC"),
+		redisAttack("a", AttackResponseStatusRunning, "Wed, 02 Jan 2019 03:00:00 UTC"),
+		redisAttack("c", AttackResponseStatusCompleted, "Wed, 02 Jan 2019 01:00:00 UTC"),
+	}
+	attacks[1].Params.Labels = map[string]string{"team": "payments"}
+	for _, a := range attacks {
+		if err := r.Add(a); err != nil {
+			t.Fatalf("Redis.Add() error = %v", err)
+		}
+	}
+
+	// Keys of other tools, and attacks that expired since they were indexed
+	f.strings["session:42"] = []byte("not an attack")
+	f.strings["vegeta:attack:d"] = []byte("not an attack")
+	f.zsets["vegeta:attacks"]["d"] = 0
+	f.zsets["vegeta:attacks"]["e"] = 0
+	f.sets["vegeta:status:completed"]["e"] = struct{}{}
+
+	tests := []struct {
+		name    string
+		filters FilterParams
+		want    []string
+	}{
+		{"All", FilterParams{}, []string{"c", "b", "a"}},
+		{"Status", FilterParams{"status": "completed"}, []string{"b", "c"}},
+		{"Filtered", FilterParams{"labels": "team=payments"}, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redisIDs(r.GetAll(tt.filters)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redis.GetAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := f.zsets["vegeta:attacks"]["e"]; ok {
+		t.Error("Redis.GetAll() did not unindex the expired attack")
+	}
+	if _, ok := f.sets["vegeta:status:completed"]["e"]; ok {
+		t.Error("Redis.GetAll() did not unindex the expired attack status")
+	}
+}
+
+func TestRedis_GetByID(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(f, 0)
+
+	if err := r.Add(redisAttack("1", AttackResponseStatusRunning, "")); err != nil {
+		t.Fatalf("Redis.Add() error = %v", err)
+	}
+
+	if got, err := r.GetByID("1"); err != nil || got.ID != "1" {
+		t.Errorf("Redis.GetByID() = %v, %v, want attack 1", got, err)
+	}
+	if _, err := r.GetByID("2"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Redis.GetByID() error = %v, want not found", err)
+	}
+
+	if got, err := r.GetInfoByID("1"); err != nil || got.ID != "1" {
+		t.Errorf("Redis.GetInfoByID() = %v, %v, want attack 1", got, err)
+	}
+	if _, err := r.GetInfoByID("2"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Redis.GetInfoByID() error = %v, want not found", err)
+	}
+}
+
+func TestRedis_Delete(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(f, 0)
+
+	if err := r.Add(redisAttack("1", AttackResponseStatusCompleted, "")); err != nil {
+		t.Fatalf("Redis.Add() error = %v", err)
+	}
+	if err := r.Delete("1"); err != nil {
+		t.Fatalf("Redis.Delete() error = %v", err)
+	}
+
+	if _, ok := f.strings["vegeta:attack:1"]; ok {
+		t.Error("Redis.Delete() did not delete the attack")
+	}
+	if len(f.zsets["vegeta:attacks"]) != 0 || len(f.sets["vegeta:status:completed"]) != 0 {
+		t.Error("Redis.Delete() did not unindex the attack")
+	}
+}
+
+func TestRedis_Reindex(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(f, 0)
+
+	if err := r.Add(redisAttack("1", AttackResponseStatusCompleted, "Wed, 02 Jan 2019 01:00:00 UTC")); err != nil {
+		t.Fatalf("Redis.Add() error = %v", err)
+	}
+	f.zsets = make(map[string]map[string]int64)
+	f.sets = make(map[string]map[string]struct{})
+	f.strings["vegeta:attack:2"] = []byte("not an attack")
+
+	if err := r.Reindex(); err != nil {
+		t.Fatalf("Redis.Reindex() error = %v", err)
+	}
+
+	if got := redisIDs(r.GetAll(FilterParams{"status": "completed"})); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Redis.GetAll() after Reindex() = %v, want [1]", got)
+	}
+}
+
+func TestRedis_Result(t *testing.T) {
+	result := []byte(strings.Repeat("vegeta result ", 100))
+
+	tests := []struct {
+		name     string
+		compress bool
+	}{
+		{"Uncompressed", false},
+		{"Compressed", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRedis()
+			r := NewRedis(func() redis.Conn { return f }, "vegeta:", 0, tt.compress)
+
+			attack := redisAttack("1", AttackResponseStatusCompleted, "")
+			attack.Result = result
+			if err := r.Add(attack); err != nil {
+				t.Fatalf("Redis.Add() error = %v", err)
+			}
+
+			if strings.Contains(string(f.strings["vegeta:attack:1"]), `"result"`) {
+				t.Error("Redis.Add() stored the result with the attack metadata")
+			}
+			if stored := f.strings["vegeta:result:1"]; bytes.HasPrefix(stored, gzipMagic) != tt.compress {
+				t.Errorf("Redis.Add() stored result compressed = %v, want %v", !tt.compress, tt.compress)
+			}
+
+			all := r.GetAll(FilterParams{})
+			if len(all) != 1 || all[0].Result != nil || all[0].ResultLen() != int64(len(result)) {
+				t.Errorf("Redis.GetAll() = %v, want metadata only with the result size", all)
+			}
+
+			info, err := r.GetInfoByID("1")
+			if err != nil || info.Result != nil || info.ResultLen() != int64(len(result)) {
+				t.Errorf("Redis.GetInfoByID() = %v, %v, want metadata only with the result size", info, err)
+			}
+
+			got, err := r.GetByID("1")
+			if err != nil || !bytes.Equal(got.Result, result) {
+				t.Errorf("Redis.GetByID() result = %s, %v, want %s", got.Result, err, result)
+			}
+
+			if err := r.Delete("1"); err != nil {
+				t.Fatalf("Redis.Delete() error = %v", err)
+			}
+			if _, ok := f.strings["vegeta:result:1"]; ok {
+				t.Error("Redis.Delete() did not delete the result")
+			}
+		})
+	}
+}
+
+func TestRedis_SpooledResult(t *testing.T) {
+	result := []byte(strings.Repeat("vegeta result ", 100))
+
+	file, err := ioutil.TempFile("", "vegeta-result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name()) // nolint: errcheck
+	if _, err := file.Write(result); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		compress bool
+	}{
+		{"Uncompressed", false},
+		{"Compressed", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRedis()
+			r := NewRedis(func() redis.Conn { return f }, "vegeta:", time.Minute, tt.compress)
+
+			attack := redisAttack("1", AttackResponseStatusCompleted, "")
+			attack.ResultPath = file.Name()
+			attack.ResultSize = int64(len(result))
+			for i := 0; i < 2; i++ {
+				if err := r.Add(attack); err != nil {
+					t.Fatalf("Redis.Add() error = %v", err)
+				}
+			}
+
+			if stored := f.strings["vegeta:result:1"]; bytes.HasPrefix(stored, gzipMagic) != tt.compress {
+				t.Errorf("Redis.Add() stored result compressed = %v, want %v", !tt.compress, tt.compress)
+			}
+			if _, ok := f.strings["vegeta:result:1:tmp"]; ok {
+				t.Error("Redis.Add() left the temporary result key")
+			}
+			if ttl := f.ttls["vegeta:result:1"]; ttl != int64(time.Minute/time.Millisecond) {
+				t.Errorf("Redis.Add() result ttl = %d, want %d", ttl, time.Minute/time.Millisecond)
+			}
+
+			got, err := r.GetByID("1")
+			if err != nil || !bytes.Equal(got.Result, result) {
+				t.Errorf("Redis.GetByID() result = %s, %v, want %s", got.Result, err, result)
+			}
+		})
+	}
+}
+
+func TestRedis_GetByID_InlineResult(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(f, 0)
+
+	f.strings["vegeta:attack:1"] = []byte(`{"id":"1","status":"completed","created_at":"","updated_at":"","result":"cmVzdWx0"}`)
+
+	got, err := r.GetByID("1")
+	if err != nil || string(got.Result) != "result" {
+		t.Errorf("Redis.GetByID() result = %s, %v, want result", got.Result, err)
+	}
+}
@@ -0,0 +1,71 @@
+package models
+
+// CompareTolerances sets how much worse a candidate attack may perform than
+// its baseline before the comparison fails
+type CompareTolerances struct {
+	// LatencyIncrease is the maximum relative increase of the mean, 50th, 95th
+	// and 99th percentile latencies, e.g. 0.1 for 10%
+	LatencyIncrease float64 `json:"latency_increase"`
+	// SuccessDecrease is the maximum absolute decrease of the success ratio,
+	// e.g. 0.001 for 0.1 percentage point
+	SuccessDecrease float64 `json:"success_decrease"`
+	// ThroughputDecrease is the maximum relative decrease of the throughput,
+	// e.g. 0.1 for 10%
+	ThroughputDecrease float64 `json:"throughput_decrease"`
+}
+
+// DefaultCompareTolerances are used for tolerances not set in the request
+var DefaultCompareTolerances = CompareTolerances{
+	LatencyIncrease:    0.1,
+	SuccessDecrease:    0.001,
+	ThroughputDecrease: 0.1,
+}
+
+// MetricDelta captures the difference of a metric between two attacks
+type MetricDelta struct {
+	Base      float64 `json:"base"`
+	Candidate float64 `json:"candidate"`
+	// Delta is the absolute difference, candidate - base
+	Delta float64 `json:"delta"`
+	// Relative is the difference relative to the base, zero if the base is zero
+	Relative float64 `json:"relative"`
+}
+
+// NewMetricDelta returns the delta between a base and candidate metric value
+func NewMetricDelta(base, candidate float64) MetricDelta {
+	d := MetricDelta{
+		Base:      base,
+		Candidate: candidate,
+		Delta:     candidate - base,
+	}
+	if base != 0 {
+		d.Relative = d.Delta / base
+	}
+	return d
+}
+
+// CompareReportResponse provides the model for a report comparison response
+// object. Latencies are in nanoseconds, and status codes are compared as their
+// share of the requests.
+type CompareReportResponse struct {
+	Base      string `json:"base"`
+	Candidate string `json:"candidate"`
+	Latencies struct {
+		Mean  MetricDelta `json:"mean"`
+		Max   MetricDelta `json:"max"`
+		P50th MetricDelta `json:"50th"`
+		P95th MetricDelta `json:"95th"`
+		P99th MetricDelta `json:"99th"`
+	} `json:"latencies"`
+	Requests    MetricDelta            `json:"requests"`
+	Rate        MetricDelta            `json:"rate"`
+	Throughput  MetricDelta            `json:"throughput"`
+	Success     MetricDelta            `json:"success"`
+	StatusCodes map[string]MetricDelta `json:"status_codes"`
+
+	Tolerances CompareTolerances `json:"tolerances"`
+	// Pass is true if the candidate is within the tolerances of the base
+	Pass bool `json:"pass"`
+	// Failures describes the checks the candidate failed
+	Failures []string `json:"failures"`
+}
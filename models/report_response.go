@@ -2,31 +2,68 @@ package models
 
 // JSONReportResponse provides the model for a report response object
 type JSONReportResponse struct {
-	ID        string `json:"id"`
+	ID string `json:"id"`
+	// Labels and Description of the attack, if any
+	Labels      map[string]string `json:"labels,omitempty"`
+	Description string            `json:"description,omitempty"`
+	// Latencies are in nanoseconds
 	Latencies struct {
-		Total int `json:"total"`
-		Mean  int `json:"mean"`
-		Max   int `json:"max"`
-		P50th int `json:"50th"`
-		P95th int `json:"95th"`
-		P99th int `json:"99th"`
+		Total int64 `json:"total"`
+		Mean  int64 `json:"mean"`
+		Min   int64 `json:"min"`
+		Max   int64 `json:"max"`
+		P50th int64 `json:"50th"`
+		P90th int64 `json:"90th"`
+		P95th int64 `json:"95th"`
+		P99th int64 `json:"99th"`
+		// Percentiles requested along with the report, keyed like "99.9th"
+		Percentiles map[string]int64 `json:"percentiles,omitempty"`
 	} `json:"latencies"`
 	BytesIn struct {
-		Total int `json:"total"`
-		Mean  int `json:"mean"`
+		Total uint64  `json:"total"`
+		Mean  float64 `json:"mean"`
 	} `json:"bytes_in"`
 	BytesOut struct {
-		Total int `json:"total"`
-		Mean  int `json:"mean"`
+		Total uint64  `json:"total"`
+		Mean  float64 `json:"mean"`
 	} `json:"bytes_out"`
-	Earliest    string         `json:"earliest"`
-	Latest      string         `json:"latest"`
-	End         string         `json:"end"`
-	Duration    int            `json:"duration"`
-	Wait        int            `json:"wait"`
-	Requests    int            `json:"requests"`
-	Rate        float64        `json:"rate"`
-	Success     int            `json:"success"`
+	Earliest string `json:"earliest"`
+	Latest   string `json:"latest"`
+	End      string `json:"end"`
+	// Duration of the attack and Wait for the last response, in nanoseconds
+	Duration int64   `json:"duration"`
+	Wait     int64   `json:"wait"`
+	Requests uint64  `json:"requests"`
+	Rate     float64 `json:"rate"`
+	// Throughput is the rate of successful requests per second
+	Throughput  float64        `json:"throughput"`
+	Success     float64        `json:"success"`
 	StatusCodes map[string]int `json:"status_codes"`
 	Errors      []string       `json:"errors"`
 }
+
+// TimeseriesReportResponse provides the model for a time series report, with
+// the metrics of an attack bucketed over its timeline
+type TimeseriesReportResponse struct {
+	ID string `json:"id"`
+	// Interval covered by each bucket, e.g. "1s"
+	Interval string             `json:"interval"`
+	Buckets  []TimeseriesBucket `json:"buckets"`
+}
+
+// TimeseriesBucket captures the metrics of the requests sent during an
+// interval. Intervals without requests have empty buckets.
+type TimeseriesBucket struct {
+	// Start of the interval, in RFC3339 format
+	Start     string `json:"start"`
+	Latencies struct {
+		Mean  int64 `json:"mean"`
+		Max   int64 `json:"max"`
+		P50th int64 `json:"50th"`
+		P95th int64 `json:"95th"`
+		P99th int64 `json:"99th"`
+	} `json:"latencies"`
+	Requests    uint64         `json:"requests"`
+	Success     float64        `json:"success"`
+	StatusCodes map[string]int `json:"status_codes"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// RetentionSweep captures the attacks evicted by a retention sweep
+type RetentionSweep struct {
+	Timestamp string   `json:"timestamp"`
+	Evicted   []string `json:"evicted"`
+	// FreedBytes is the size of the results of the evicted attacks
+	FreedBytes int64 `json:"freed_bytes"`
+}
+
+// RetentionResponse captures the retention policy of ended attacks, and the
+// attacks it evicted
+type RetentionResponse struct {
+	// MaxAge is the time ended attacks are kept for, empty if unlimited
+	MaxAge string `json:"max_age,omitempty"`
+	// MaxCount is the maximum number of attacks kept, zero if unlimited
+	MaxCount int `json:"max_count"`
+	// MaxBytes is the maximum size of the results kept, zero if unlimited
+	MaxBytes int64 `json:"max_bytes"`
+
+	// EvictedTotal is the number of attacks evicted since the server started
+	EvictedTotal uint64 `json:"evicted_total"`
+	// EvictedBytesTotal is the size of the results evicted since the server
+	// started
+	EvictedBytesTotal uint64          `json:"evicted_bytes_total"`
+	LastSweep         *RetentionSweep `json:"last_sweep,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+// ScheduleParams request parameters, for attacks submitted to run at a later
+// time or on a recurring basis
+type ScheduleParams struct {
+	AttackParams
+
+	// StartAt is an RFC3339 timestamp. With no cron expression, the attack is
+	// run once at StartAt. Otherwise the cron schedule starts at StartAt.
+	StartAt string `json:"start_at,omitempty"`
+	// Cron is a standard 5 field cron expression, or a predefined schedule
+	// such as "@hourly", on which the attack is run
+	Cron string `json:"cron,omitempty"`
+}
+
+// ScheduleInfo encapsulates the schedule information for attacks
+// submitted to the dispatcher with a start time or a cron expression
+type ScheduleInfo struct {
+	// ID is a schedule UUID generated for each schedule submitted
+	ID string `json:"id,omitempty"`
+	// Status captures the schedule status
+	Status ScheduleStatus `json:"status,omitempty"`
+	// Params captures the schedule and attack parameters
+	Params ScheduleParams `json:"params,omitempty"`
+	// NextRunAt is the time of the next attack, empty if none is due
+	NextRunAt string `json:"next_run_at,omitempty"`
+	// AttackIDs lists the IDs of the attacks created by the schedule
+	AttackIDs []string `json:"attack_ids"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// ScheduleResponse with schedule UUID and ScheduleStatus
+type ScheduleResponse ScheduleInfo
+
+// ScheduleStatus as a string enum
+type ScheduleStatus string
+
+const (
+
+	// ScheduleStatusActive captures enum value "active"
+	ScheduleStatusActive ScheduleStatus = "active"
+
+	// ScheduleStatusPaused captures enum value "paused"
+	ScheduleStatusPaused ScheduleStatus = "paused"
+
+	// ScheduleStatusDone captures enum value "done"
+	ScheduleStatusDone ScheduleStatus = "done"
+)
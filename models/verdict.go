@@ -0,0 +1,18 @@
+package models
+
+// Verdict captures the outcome of the assertions of a completed attack
+type Verdict struct {
+	// Pass is true if all the assertions passed
+	Pass       bool              `json:"pass"`
+	Assertions []AssertionResult `json:"assertions"`
+}
+
+// AssertionResult captures the outcome of a single attack assertion
+type AssertionResult struct {
+	Assertion string `json:"assertion"`
+	Pass      bool   `json:"pass"`
+	// Actual is the measured value of the asserted metric
+	Actual string `json:"actual,omitempty"`
+	// Error is set if the assertion could not be evaluated
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// WebhookEvent provides the model for the payload POSTed to the webhooks on
+// attack status transitions
+type WebhookEvent struct {
+	// ID is a UUID generated for each event, shared by its delivery retries
+	ID string `json:"id"`
+	// Status is the attack status the attack transitioned to
+	Status AttackStatus `json:"status"`
+	Attack AttackInfo   `json:"attack"`
+	// Report summarises the attack metrics of completed attacks
+	Report    *AttackMetrics `json:"report,omitempty"`
+	Timestamp string         `json:"timestamp"`
+}
+
+// WebhookDelivery captures a single attempt at delivering a webhook event
+type WebhookDelivery struct {
+	EventID string       `json:"event_id"`
+	Status  AttackStatus `json:"status"`
+	URL     string       `json:"url"`
+	Attempt int          `json:"attempt"`
+	// Success is true if the webhook responded with a 2xx status code
+	Success bool `json:"success"`
+	// StatusCode of the webhook response, zero if the request failed
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Timestamp  string `json:"timestamp"`
+}
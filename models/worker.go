@@ -0,0 +1,21 @@
+package models
+
+// Worker captures a vegeta-server worker registered with a coordinator, which
+// runs a share of the coordinator attacks
+type Worker struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Healthy is unset once a health check of the worker fails, and set again
+	// once the worker registers or passes a health check
+	Healthy bool `json:"healthy"`
+	// Failures is the number of consecutive failed health checks
+	Failures     int    `json:"failures"`
+	RegisteredAt string `json:"registered_at"`
+	LastSeen     string `json:"last_seen"`
+}
+
+// WorkerRegistration request body, sent by workers joining a coordinator
+type WorkerRegistration struct {
+	// URL the coordinator reaches the worker at
+	URL string `json:"url" binding:"required,url"`
+}
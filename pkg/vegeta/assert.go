@@ -0,0 +1,161 @@
+package vegeta
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+)
+
+// assertionRe matches assertions of the form "<metric> <op> <value>", where
+// percentages of the requested rate may read "95% of requested"
+var assertionRe = regexp.MustCompile(`^\s*([a-z0-9]+)\s*(<=|>=|<|>)\s*(\S+?)(\s+of\s+requested)?\s*$`)
+
+// assertion is a parsed attack assertion. The value is in the unit of the
+// metric: nanoseconds for latencies, a ratio for success and requests per
+// second for the rate and throughput.
+type assertion struct {
+	metric string
+	op     string
+	value  float64
+}
+
+// parseAssertion parses an assertion of the attack params. Percentages of the
+// rate and throughput are relative to the requested attack rate, and are
+// refused for ramped attacks, whose rate is not constant.
+func parseAssertion(expr string, params models.AttackParams) (assertion, error) {
+	match := assertionRe.FindStringSubmatch(expr)
+	if match == nil {
+		return assertion{}, fmt.Errorf("invalid assertion %q, want \"<metric> <op> <value>\"", expr)
+	}
+
+	a := assertion{metric: match[1], op: match[2]}
+	value := match[3]
+	percent := strings.HasSuffix(value, "%")
+	if match[4] != "" && !percent {
+		return assertion{}, fmt.Errorf("invalid assertion %q, only percentages can be of requested", expr)
+	}
+
+	var err error
+	switch a.metric {
+	case "mean", "max", "p50", "p95", "p99":
+		var d time.Duration
+		d, err = time.ParseDuration(value)
+		a.value = float64(d)
+	case "success":
+		a.value, err = parseRatio(value)
+	case "rate", "throughput":
+		if percent {
+			if params.Ramp != nil {
+				return assertion{}, fmt.Errorf("invalid assertion %q, percentages need a constant rate", expr)
+			}
+			a.value, err = parseRatio(value)
+			a.value *= float64(params.Rate)
+		} else {
+			a.value, err = strconv.ParseFloat(value, 64)
+		}
+	case "requests":
+		a.value, err = strconv.ParseFloat(value, 64)
+	default:
+		return assertion{}, fmt.Errorf("invalid assertion %q, unknown metric %s", expr, a.metric)
+	}
+	if err != nil {
+		return assertion{}, errors.Wrap(err, fmt.Sprintf("invalid assertion %q", expr))
+	}
+
+	return a, nil
+}
+
+// parseRatio parses a ratio, either as a float or as a percentage
+func parseRatio(value string) (float64, error) {
+	if strings.HasSuffix(value, "%") {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		return f / 100, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// actual returns the measured value of the asserted metric, and its string
+// representation
+func (a assertion) actual(m models.AttackMetrics) (float64, string) {
+	var latency int64
+	switch a.metric {
+	case "mean":
+		latency = m.Latencies.Mean
+	case "max":
+		latency = m.Latencies.Max
+	case "p50":
+		latency = m.Latencies.P50th
+	case "p95":
+		latency = m.Latencies.P95th
+	case "p99":
+		latency = m.Latencies.P99th
+	case "success":
+		return m.Success, strconv.FormatFloat(m.Success, 'g', 6, 64)
+	case "rate":
+		return m.Rate, strconv.FormatFloat(m.Rate, 'g', 6, 64)
+	case "throughput":
+		return m.Throughput, strconv.FormatFloat(m.Throughput, 'g', 6, 64)
+	case "requests":
+		return float64(m.Requests), strconv.FormatUint(m.Requests, 10)
+	}
+	return float64(latency), time.Duration(latency).String()
+}
+
+func (a assertion) holds(actual float64) bool {
+	switch a.op {
+	case "<":
+		return actual < a.value
+	case "<=":
+		return actual <= a.value
+	case ">":
+		return actual > a.value
+	default:
+		return actual >= a.value
+	}
+}
+
+// ValidateAssertions checks the assertions of the attack params without
+// running the attack.
+func ValidateAssertions(params models.AttackParams) error {
+	for _, expr := range params.Assertions {
+		if _, err := parseAssertion(expr, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EvaluateAssertions evaluates the assertions of the attack params against
+// the attack metrics. It returns nil if the attack has no assertions.
+func EvaluateAssertions(params models.AttackParams, m models.AttackMetrics) *models.Verdict {
+	if len(params.Assertions) == 0 {
+		return nil
+	}
+
+	verdict := &models.Verdict{
+		Pass:       true,
+		Assertions: make([]models.AssertionResult, 0, len(params.Assertions)),
+	}
+	for _, expr := range params.Assertions {
+		result := models.AssertionResult{Assertion: expr}
+
+		a, err := parseAssertion(expr, params)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			var actual float64
+			actual, result.Actual = a.actual(m)
+			result.Pass = a.holds(actual)
+		}
+
+		verdict.Pass = verdict.Pass && result.Pass
+		verdict.Assertions = append(verdict.Assertions, result)
+	}
+
+	return verdict
+}
@@ -0,0 +1,92 @@
+package vegeta
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"vegeta-server/models"
+)
+
+func Test_parseAssertion(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    assertion
+		wantErr bool
+	}{
+		{expr: "p99 < 300ms", want: assertion{"p99", "<", float64(300 * time.Millisecond)}},
+		{expr: "mean<=1s", want: assertion{"mean", "<=", float64(time.Second)}},
+		{expr: "success >= 0.999", want: assertion{"success", ">=", 0.999}},
+		{expr: "success >= 99.5%", want: assertion{"success", ">=", 0.995}},
+		{expr: "rate >= 95% of requested", want: assertion{"rate", ">=", 95}},
+		{expr: "throughput > 50", want: assertion{"throughput", ">", 50}},
+		{expr: "requests >= 1000", want: assertion{"requests", ">=", 1000}},
+		{expr: "p99 300ms", wantErr: true},
+		{expr: "p99 < fast", wantErr: true},
+		{expr: "p42 < 300ms", wantErr: true},
+		{expr: "rate >= 95 of requested", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parseAssertion(tt.expr, models.AttackParams{Rate: 100})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAssertion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAssertion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAssertions_Ramp(t *testing.T) {
+	params := models.AttackParams{
+		Rate:       100,
+		Ramp:       &models.Ramp{Type: models.RampTypeLinear, To: 200},
+		Assertions: []string{"rate >= 95", "throughput > 50"},
+	}
+	if err := ValidateAssertions(params); err != nil {
+		t.Errorf("ValidateAssertions() error = %v", err)
+	}
+
+	// The rate of ramped attacks changes, so percentages of it are refused
+	for _, expr := range []string{"rate >= 95% of requested", "throughput >= 90%"} {
+		params.Assertions = []string{expr}
+		if err := ValidateAssertions(params); err == nil {
+			t.Errorf("ValidateAssertions(%q) want error for a ramped attack", expr)
+		}
+	}
+}
+
+func TestEvaluateAssertions(t *testing.T) {
+	m := models.AttackMetrics{
+		Requests: 1000,
+		Rate:     98,
+		Success:  0.998,
+	}
+	m.Latencies.P99th = int64(250 * time.Millisecond)
+
+	if got := EvaluateAssertions(models.AttackParams{Rate: 100}, m); got != nil {
+		t.Errorf("EvaluateAssertions() = %v, want nil without assertions", got)
+	}
+
+	params := models.AttackParams{
+		Rate:       100,
+		Assertions: []string{"p99 < 300ms", "rate >= 95% of requested", "success >= 0.999"},
+	}
+	want := &models.Verdict{
+		Pass: false,
+		Assertions: []models.AssertionResult{
+			{Assertion: "p99 < 300ms", Pass: true, Actual: "250ms"},
+			{Assertion: "rate >= 95% of requested", Pass: true, Actual: "98"},
+			{Assertion: "success >= 0.999", Pass: false, Actual: "0.998"},
+		},
+	}
+	if got := EvaluateAssertions(params, m); !reflect.DeepEqual(got, want) {
+		t.Errorf("EvaluateAssertions() = %v, want %v", got, want)
+	}
+
+	params.Assertions = []string{"p99 < 300ms"}
+	if got := EvaluateAssertions(params, m); !got.Pass {
+		t.Errorf("EvaluateAssertions() = %v, want pass", got)
+	}
+}
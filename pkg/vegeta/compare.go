@@ -0,0 +1,115 @@
+package vegeta
+
+import (
+	"fmt"
+	"io"
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+// NewAttackMetricsFromReader takes in an io.Reader with the vegeta gob encoded
+// result and returns the aggregated attack metrics
+func NewAttackMetricsFromReader(reader io.Reader, id string) (models.AttackMetrics, error) {
+	dec := vegeta.DecoderFor(reader)
+	if dec == nil {
+		return models.AttackMetrics{}, fmt.Errorf("unknown result encoding")
+	}
+
+	m := vegeta.Metrics{}
+	h := NewLatencyHistogram()
+	for {
+		var r vegeta.Result
+		err := dec.Decode(&r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return models.AttackMetrics{}, errors.Wrap(err, "failed to decode result")
+		}
+
+		m.Add(&r)
+		h.Add(&r)
+	}
+
+	return NewAttackMetrics(id, &m, h), nil
+}
+
+// Compare the metrics of a candidate attack with its baseline, and check the
+// candidate against the tolerances
+func Compare(base, candidate models.AttackMetrics, tol models.CompareTolerances) models.CompareReportResponse {
+	resp := models.CompareReportResponse{
+		Base:        base.ID,
+		Candidate:   candidate.ID,
+		StatusCodes: make(map[string]models.MetricDelta),
+		Tolerances:  tol,
+		Failures:    make([]string, 0),
+	}
+
+	latency := func(b, c int64) models.MetricDelta {
+		return models.NewMetricDelta(float64(b), float64(c))
+	}
+	resp.Latencies.Mean = latency(base.Latencies.Mean, candidate.Latencies.Mean)
+	resp.Latencies.Max = latency(base.Latencies.Max, candidate.Latencies.Max)
+	resp.Latencies.P50th = latency(base.Latencies.P50th, candidate.Latencies.P50th)
+	resp.Latencies.P95th = latency(base.Latencies.P95th, candidate.Latencies.P95th)
+	resp.Latencies.P99th = latency(base.Latencies.P99th, candidate.Latencies.P99th)
+
+	resp.Requests = models.NewMetricDelta(float64(base.Requests), float64(candidate.Requests))
+	resp.Rate = models.NewMetricDelta(base.Rate, candidate.Rate)
+	resp.Throughput = models.NewMetricDelta(base.Throughput, candidate.Throughput)
+	resp.Success = models.NewMetricDelta(base.Success, candidate.Success)
+
+	for code := range base.StatusCodes {
+		resp.StatusCodes[code] = models.NewMetricDelta(share(base, code), share(candidate, code))
+	}
+	for code := range candidate.StatusCodes {
+		resp.StatusCodes[code] = models.NewMetricDelta(share(base, code), share(candidate, code))
+	}
+
+	// The max latency is left out of the verdict, as a single slow request
+	// is enough to move it.
+	for _, l := range []struct {
+		name  string
+		delta models.MetricDelta
+	}{
+		{"mean", resp.Latencies.Mean},
+		{"50th", resp.Latencies.P50th},
+		{"95th", resp.Latencies.P95th},
+		{"99th", resp.Latencies.P99th},
+	} {
+		if l.delta.Relative > tol.LatencyIncrease {
+			resp.Failures = append(resp.Failures, fmt.Sprintf(
+				"%s latency increased by %.1f%%, tolerance %.1f%%",
+				l.name, l.delta.Relative*100, tol.LatencyIncrease*100,
+			))
+		}
+	}
+
+	if -resp.Success.Delta > tol.SuccessDecrease {
+		resp.Failures = append(resp.Failures, fmt.Sprintf(
+			"success ratio decreased by %.4f, tolerance %.4f",
+			-resp.Success.Delta, tol.SuccessDecrease,
+		))
+	}
+
+	if -resp.Throughput.Relative > tol.ThroughputDecrease {
+		resp.Failures = append(resp.Failures, fmt.Sprintf(
+			"throughput decreased by %.1f%%, tolerance %.1f%%",
+			-resp.Throughput.Relative*100, tol.ThroughputDecrease*100,
+		))
+	}
+
+	resp.Pass = len(resp.Failures) == 0
+
+	return resp
+}
+
+// share returns the share of the requests of an attack with the status code
+func share(m models.AttackMetrics, code string) float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+	return float64(m.StatusCodes[code]) / float64(m.Requests)
+}
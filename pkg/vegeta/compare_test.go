@@ -0,0 +1,100 @@
+package vegeta
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+	"vegeta-server/models"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func newTestAttackMetrics(id string, p99 int64, success, throughput float64, codes map[string]int) models.AttackMetrics {
+	m := models.AttackMetrics{
+		ID:          id,
+		Success:     success,
+		Throughput:  throughput,
+		StatusCodes: codes,
+	}
+	m.Latencies.Mean = 100
+	m.Latencies.P50th = 100
+	m.Latencies.P95th = 100
+	m.Latencies.P99th = p99
+	for _, count := range codes {
+		m.Requests += uint64(count)
+	}
+	return m
+}
+
+func TestCompare(t *testing.T) {
+	base := newTestAttackMetrics("base", 200, 1, 100, map[string]int{"200": 100})
+	tests := []struct {
+		name         string
+		candidate    models.AttackMetrics
+		wantPass     bool
+		wantFailures int
+	}{
+		{
+			name:      "within tolerances",
+			candidate: newTestAttackMetrics("candidate", 210, 1, 95, map[string]int{"200": 100}),
+			wantPass:  true,
+		},
+		{
+			name:         "latency regression",
+			candidate:    newTestAttackMetrics("candidate", 300, 1, 100, map[string]int{"200": 100}),
+			wantFailures: 1,
+		},
+		{
+			name:         "success and throughput regression",
+			candidate:    newTestAttackMetrics("candidate", 200, 0.9, 80, map[string]int{"200": 90, "500": 10}),
+			wantFailures: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(base, tt.candidate, models.DefaultCompareTolerances)
+			if got.Pass != tt.wantPass || len(got.Failures) != tt.wantFailures {
+				t.Errorf("Compare() pass = %v, failures = %v", got.Pass, got.Failures)
+			}
+		})
+	}
+
+	got := Compare(base, newTestAttackMetrics("candidate", 300, 0.9, 80, map[string]int{"200": 90, "500": 10}), models.DefaultCompareTolerances) // nolint: lll
+	wantP99 := models.MetricDelta{Base: 200, Candidate: 300, Delta: 100, Relative: 0.5}
+	if !reflect.DeepEqual(got.Latencies.P99th, wantP99) {
+		t.Errorf("Compare() 99th = %v, want %v", got.Latencies.P99th, wantP99)
+	}
+	wantCodes := map[string]models.MetricDelta{
+		"200": models.NewMetricDelta(1, 0.9),
+		"500": {Base: 0, Candidate: 0.1, Delta: 0.1},
+	}
+	if !reflect.DeepEqual(got.StatusCodes, wantCodes) {
+		t.Errorf("Compare() status codes = %v, want %v", got.StatusCodes, wantCodes)
+	}
+}
+
+func TestNewAttackMetricsFromReader(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := vegeta.NewEncoder(buf)
+	now := time.Now()
+	for i, code := range []uint16{200, 200, 500} {
+		_ = enc.Encode(&vegeta.Result{
+			Code:      code,
+			Timestamp: now.Add(time.Duration(i) * time.Second),
+			Latency:   time.Duration(i+1) * time.Millisecond,
+		})
+	}
+
+	got, err := NewAttackMetricsFromReader(buf, "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "123" || got.Requests != 3 || got.StatusCodes["500"] != 1 || got.Latencies.Max != int64(3*time.Millisecond) {
+		t.Errorf("NewAttackMetricsFromReader() = %+v", got)
+	}
+
+	if _, err = NewAttackMetricsFromReader(bytes.NewBufferString("invalid"), "123"); err == nil {
+		t.Error("NewAttackMetricsFromReader() want decode error")
+	}
+}
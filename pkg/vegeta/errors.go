@@ -0,0 +1,34 @@
+package vegeta
+
+import (
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+)
+
+// categorizedError is an attack error along with its category
+type categorizedError struct {
+	category models.AttackErrorCategory
+	err      error
+}
+
+func (e *categorizedError) Error() string {
+	return e.err.Error()
+}
+
+// categorize sets the category of an attack error, unless it already has one
+func categorize(category models.AttackErrorCategory, err error) error {
+	if _, ok := errors.Cause(err).(*categorizedError); ok {
+		return err
+	}
+	return &categorizedError{category, err}
+}
+
+// ErrorCategory returns the category of an error returned by Attack. Errors
+// without a category are internal errors.
+func ErrorCategory(err error) models.AttackErrorCategory {
+	if e, ok := errors.Cause(err).(*categorizedError); ok {
+		return e.category
+	}
+	return models.AttackErrorCategoryInternal
+}
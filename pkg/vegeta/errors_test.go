@@ -0,0 +1,62 @@
+package vegeta
+
+import (
+	"fmt"
+	"testing"
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+)
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		name   string
+		params models.AttackParams
+		want   models.AttackErrorCategory
+	}{
+		{
+			name:   "Param Validation",
+			params: models.AttackParams{Duration: "forever"},
+			want:   models.AttackErrorCategoryParamValidation,
+		},
+		{
+			name:   "Resolve",
+			params: models.AttackParams{Duration: "1s", Rate: 1, Laddr: "invalid..host"},
+			want:   models.AttackErrorCategoryResolve,
+		},
+		{
+			name: "TLS Config",
+			params: models.AttackParams{
+				Duration: "1s",
+				Rate:     1,
+				Laddr:    "0.0.0.0",
+				Target:   models.Target{Method: "GET", URL: "https://localhost"},
+				Cert:     "invalid",
+				Key:      "invalid",
+			},
+			want: models.AttackErrorCategoryTLSConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Attack("123", tt.params, make(chan struct{}), nil)
+			if err == nil {
+				t.Fatal("Attack() error = nil, want an error")
+			}
+			if got := ErrorCategory(err); got != tt.want {
+				t.Errorf("ErrorCategory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := ErrorCategory(fmt.Errorf("oops")); got != models.AttackErrorCategoryInternal {
+		t.Errorf("ErrorCategory() = %v, want %v", got, models.AttackErrorCategoryInternal)
+	}
+
+	// The first category set is kept
+	err := categorize(models.AttackErrorCategoryEncode, fmt.Errorf("oops"))
+	err = categorize(models.AttackErrorCategoryParamValidation, errors.Wrap(err, "wrapped"))
+	if got := ErrorCategory(err); got != models.AttackErrorCategoryEncode {
+		t.Errorf("ErrorCategory() = %v, want %v", got, models.AttackErrorCategoryEncode)
+	}
+}
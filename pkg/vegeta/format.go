@@ -23,58 +23,76 @@ func NewFormat(typ string) Format {
 		return NewBinaryFormat()
 	case "histogram":
 		return NewHistogramFormat()
+	case "csv":
+		return NewCSVFormat()
+	case "jsonl":
+		return NewJSONLinesFormat()
+	case "timeseries":
+		return NewTimeseriesFormat()
+	case "plot":
+		return NewPlotFormat()
+	case "hdrplot":
+		return NewHDRPlotFormat()
 	}
 	return NewJSONFormat() // default
 }
 
 // JSONFormat typedef for query param "json"
-type JSONFormat string
+type JSONFormat struct {
+	repr string
+	meta MetaInfo
+}
 
 // NewJSONFormat returns a new Format of JSON type
 func NewJSONFormat() *JSONFormat {
-	f := JSONFormat("json")
-	return &f
+	return &JSONFormat{
+		repr: "json",
+		meta: make(MetaInfo),
+	}
 }
 
 // SetMeta will set the meta information
-// no-op method
 func (j *JSONFormat) SetMeta(key, value string) {
+	j.meta[key] = value
 }
 
 // String implements Stringer for JSONFormat
 func (j *JSONFormat) String() string {
-	return string(*j)
+	return j.repr
 }
 
 // Meta returns the meta information stored in the Format
-// no-op method
-func (j *JSONFormat) Meta() (m MetaInfo) {
-	return nil
+func (j *JSONFormat) Meta() MetaInfo {
+	return j.meta
 }
 
 // TextFormat typedef for query param "text"
-type TextFormat string
+type TextFormat struct {
+	repr string
+	meta MetaInfo
+}
 
 // NewTextFormat returns a new Format of Text type
 func NewTextFormat() *TextFormat {
-	f := TextFormat("text")
-	return &f
+	return &TextFormat{
+		repr: "text",
+		meta: make(MetaInfo),
+	}
 }
 
 // SetMeta will set the meta information
-// no-op method
-func (j *TextFormat) SetMeta(key, value string) {
+func (t *TextFormat) SetMeta(key, value string) {
+	t.meta[key] = value
 }
 
 // String implements Stringer for TextFormat
-func (j *TextFormat) String() string {
-	return string(*j)
+func (t *TextFormat) String() string {
+	return t.repr
 }
 
 // Meta returns the meta information stored in the Format
-// no-op method
-func (j *TextFormat) Meta() (m MetaInfo) {
-	return nil
+func (t *TextFormat) Meta() MetaInfo {
+	return t.meta
 }
 
 // BinaryFormat typedef for query param "binary"
@@ -102,6 +120,81 @@ func (b *BinaryFormat) Meta() (m MetaInfo) {
 	return
 }
 
+// CSVFormat typedef for query param "csv"
+type CSVFormat string
+
+// NewCSVFormat returns a new Format of CSV type
+func NewCSVFormat() *CSVFormat {
+	f := CSVFormat("csv")
+	return &f
+}
+
+// SetMeta will set the meta information
+// no-op method
+func (c *CSVFormat) SetMeta(key, value string) {
+}
+
+// String implements Stringer for CSVFormat
+func (c *CSVFormat) String() string {
+	return string(*c)
+}
+
+// Meta returns the meta information stored in the Format
+// no-op method
+func (c *CSVFormat) Meta() (m MetaInfo) {
+	return
+}
+
+// JSONLinesFormat typedef for query param "jsonl"
+type JSONLinesFormat string
+
+// NewJSONLinesFormat returns a new Format of JSON-lines type
+func NewJSONLinesFormat() *JSONLinesFormat {
+	f := JSONLinesFormat("jsonl")
+	return &f
+}
+
+// SetMeta will set the meta information
+// no-op method
+func (j *JSONLinesFormat) SetMeta(key, value string) {
+}
+
+// String implements Stringer for JSONLinesFormat
+func (j *JSONLinesFormat) String() string {
+	return string(*j)
+}
+
+// Meta returns the meta information stored in the Format
+// no-op method
+func (j *JSONLinesFormat) Meta() (m MetaInfo) {
+	return
+}
+
+// HDRPlotFormat typedef for query param "hdrplot"
+type HDRPlotFormat string
+
+// NewHDRPlotFormat returns a new Format of HDR histogram plot type
+func NewHDRPlotFormat() *HDRPlotFormat {
+	f := HDRPlotFormat("hdrplot")
+	return &f
+}
+
+// SetMeta will set the meta information
+// no-op method
+func (h *HDRPlotFormat) SetMeta(key, value string) {
+}
+
+// String implements Stringer for HDRPlotFormat
+func (h *HDRPlotFormat) String() string {
+	return string(*h)
+}
+
+// Meta returns the meta information stored in the Format
+// no-op method
+func (h *HDRPlotFormat) Meta() (m MetaInfo) {
+	return
+}
+
 // HistogramFormat typedef for query param "histogram"
 type HistogramFormat struct {
 	repr string
@@ -130,3 +223,61 @@ func (h *HistogramFormat) String() string {
 func (h *HistogramFormat) Meta() MetaInfo {
 	return h.meta
 }
+
+// TimeseriesFormat typedef for query param "timeseries"
+type TimeseriesFormat struct {
+	repr string
+	meta MetaInfo
+}
+
+// NewTimeseriesFormat returns a new Format of Timeseries type
+func NewTimeseriesFormat() *TimeseriesFormat {
+	return &TimeseriesFormat{
+		repr: "timeseries",
+		meta: make(MetaInfo),
+	}
+}
+
+// SetMeta will set the meta information
+func (t *TimeseriesFormat) SetMeta(key, value string) {
+	t.meta[key] = value
+}
+
+// String implements Stringer for TimeseriesFormat
+func (t *TimeseriesFormat) String() string {
+	return t.repr
+}
+
+// Meta returns the meta information stored in the Format
+func (t *TimeseriesFormat) Meta() MetaInfo {
+	return t.meta
+}
+
+// PlotFormat typedef for query param "plot"
+type PlotFormat struct {
+	repr string
+	meta MetaInfo
+}
+
+// NewPlotFormat returns a new Format of Plot type
+func NewPlotFormat() *PlotFormat {
+	return &PlotFormat{
+		repr: "plot",
+		meta: make(MetaInfo),
+	}
+}
+
+// SetMeta will set the meta information
+func (p *PlotFormat) SetMeta(key, value string) {
+	p.meta[key] = value
+}
+
+// String implements Stringer for PlotFormat
+func (p *PlotFormat) String() string {
+	return p.repr
+}
+
+// Meta returns the meta information stored in the Format
+func (p *PlotFormat) Meta() MetaInfo {
+	return p.meta
+}
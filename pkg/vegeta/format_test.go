@@ -31,27 +31,31 @@ func TestNewFormat(t *testing.T) {
 			},
 			want: want{
 				typ: "json",
-				mta: nil,
+				mta: MetaInfo{"bucket": DefaultBucketString},
 			},
 			setup: func(w *want) {
-				x := JSONFormat("json")
-				w.frm = &x
+				w.frm = &JSONFormat{
+					repr: "json",
+					meta: make(MetaInfo),
+				}
 			},
 		},
 		{
 			name: "type: text",
 			args: args{
 				typ: "text",
-				key: "bucket",
-				val: DefaultBucketString,
+				key: "percentiles",
+				val: "99.9",
 			},
 			want: want{
 				typ: "text",
-				mta: nil,
+				mta: MetaInfo{"percentiles": "99.9"},
 			},
 			setup: func(w *want) {
-				x := TextFormat("text")
-				w.frm = &x
+				w.frm = &TextFormat{
+					repr: "text",
+					meta: make(MetaInfo),
+				}
 			},
 		},
 		{
@@ -88,6 +92,90 @@ func TestNewFormat(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "type: csv",
+			args: args{
+				typ: "csv",
+				key: "bucket",
+				val: DefaultBucketString,
+			},
+			want: want{
+				typ: "csv",
+				mta: nil,
+			},
+			setup: func(w *want) {
+				x := CSVFormat("csv")
+				w.frm = &x
+			},
+		},
+		{
+			name: "type: jsonl",
+			args: args{
+				typ: "jsonl",
+				key: "bucket",
+				val: DefaultBucketString,
+			},
+			want: want{
+				typ: "jsonl",
+				mta: nil,
+			},
+			setup: func(w *want) {
+				x := JSONLinesFormat("jsonl")
+				w.frm = &x
+			},
+		},
+		{
+			name: "type: timeseries",
+			args: args{
+				typ: "timeseries",
+				key: "interval",
+				val: DefaultIntervalString,
+			},
+			want: want{
+				typ: "timeseries",
+				mta: MetaInfo{"interval": DefaultIntervalString},
+			},
+			setup: func(w *want) {
+				w.frm = &TimeseriesFormat{
+					repr: "timeseries",
+					meta: make(MetaInfo),
+				}
+			},
+		},
+		{
+			name: "type: plot",
+			args: args{
+				typ: "plot",
+				key: "threshold",
+				val: DefaultThresholdString,
+			},
+			want: want{
+				typ: "plot",
+				mta: MetaInfo{"threshold": DefaultThresholdString},
+			},
+			setup: func(w *want) {
+				w.frm = &PlotFormat{
+					repr: "plot",
+					meta: make(MetaInfo),
+				}
+			},
+		},
+		{
+			name: "type: hdrplot",
+			args: args{
+				typ: "hdrplot",
+				key: "bucket",
+				val: DefaultBucketString,
+			},
+			want: want{
+				typ: "hdrplot",
+				mta: nil,
+			},
+			setup: func(w *want) {
+				x := HDRPlotFormat("hdrplot")
+				w.frm = &x
+			},
+		},
 		{
 			name: "type: unknown",
 			args: args{
@@ -97,11 +185,13 @@ func TestNewFormat(t *testing.T) {
 			},
 			want: want{
 				typ: "json",
-				mta: nil,
+				mta: MetaInfo{"bucket": DefaultBucketString},
 			},
 			setup: func(w *want) {
-				x := JSONFormat("json")
-				w.frm = &x
+				w.frm = &JSONFormat{
+					repr: "json",
+					meta: make(MetaInfo),
+				}
 			},
 		},
 	}
@@ -16,7 +16,7 @@ import (
 
 // AttackOpts aggregates the attack function command options
 type AttackOpts struct {
-	Target      vegeta.Target
+	Targets     []vegeta.Target
 	Name        string
 	Body        string
 	Cert        string
@@ -27,7 +27,7 @@ type AttackOpts struct {
 	Insecure    bool
 	Duration    time.Duration
 	Timeout     time.Duration
-	Rate        vegeta.Rate
+	Pacer       vegeta.Pacer
 	Workers     uint64
 	Connections int
 	Redirects   int
@@ -39,14 +39,18 @@ type AttackOpts struct {
 
 // NewAttackOptsFromAttackParams adapts the models AttackParams to the vegeta specific options.
 func NewAttackOptsFromAttackParams(name string, params models.AttackParams) (*AttackOpts, error) {
-	rate := vegeta.Rate{Freq: params.Rate, Per: time.Second}
-
 	// Set Duration
 	dur, err := time.ParseDuration(params.Duration)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse duration")
 	}
 
+	// Set the pacer, constant unless a ramp is set
+	pacer, err := NewPacer(params.Rate, params.Ramp, dur)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set pacer")
+	}
+
 	// Set timeout
 	timeout, _ := time.ParseDuration(params.Timeout)
 
@@ -62,7 +66,10 @@ func NewAttackOptsFromAttackParams(name string, params models.AttackParams) (*At
 	// Set local address
 	laddr, err := net.ResolveIPAddr("ip", params.Laddr)
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("failed to resolve IP address: %s", params.Laddr))
+		return nil, categorize(
+			models.AttackErrorCategoryResolve,
+			errors.Wrap(err, fmt.Sprintf("failed to resolve IP address: %s", params.Laddr)),
+		)
 	}
 
 	bBody, err := base64.StdEncoding.DecodeString(params.Body)
@@ -70,20 +77,18 @@ func NewAttackOptsFromAttackParams(name string, params models.AttackParams) (*At
 		return nil, errors.Wrap(err, "failed to decode params.Body")
 	}
 
-	// Set Target
-	tgt := vegeta.Target{
-		Method: params.Target.Method,
-		URL:    params.Target.URL,
-		Header: hdr,
-		Body:   bBody,
+	// Set Targets
+	tgts, err := newTargets(params, hdr, bBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set targets")
 	}
 
 	opts := &AttackOpts{
 		Name:      name,
-		Target:    tgt,
+		Targets:   tgts,
 		Duration:  dur,
 		Timeout:   timeout,
-		Rate:      rate,
+		Pacer:     pacer,
 		Redirects: int(params.Redirects),
 		MaxBody:   params.MaxBody,
 		Keepalive: params.Keepalive,
@@ -100,3 +105,68 @@ func NewAttackOptsFromAttackParams(name string, params models.AttackParams) (*At
 
 	return opts, nil
 }
+
+// ValidateTargets checks that the attack params specify exactly one of a
+// target, a list of targets or a target file, and that the targets can be
+// built, without running the attack.
+func ValidateTargets(params models.AttackParams) error {
+	_, err := newTargets(params, nil, nil)
+	return err
+}
+
+// newTargets builds the vegeta targets from either the single params.Target,
+// the params.Targets list or the params.TargetFile. Target level headers are
+// added to the attack level headers, and a target level body overrides the
+// attack level body.
+func newTargets(params models.AttackParams, hdr http.Header, body []byte) ([]vegeta.Target, error) {
+	hasTarget := params.Target.URL != ""
+	hasTargets := len(params.Targets) > 0
+	hasTargetFile := params.TargetFile != nil
+
+	var targets []models.Target
+	switch {
+	case hasTarget && hasTargets, hasTarget && hasTargetFile, hasTargets && hasTargetFile:
+		return nil, fmt.Errorf("target, targets and target-file are mutually exclusive")
+	case hasTarget:
+		targets = []models.Target{params.Target}
+	case hasTargets:
+		targets = params.Targets
+	case hasTargetFile:
+		return NewTargetsFromFile(*params.TargetFile, hdr, body)
+	default:
+		return nil, fmt.Errorf("no target specified")
+	}
+
+	tgts := make([]vegeta.Target, 0, len(targets))
+	for i, t := range targets {
+		if t.URL == "" {
+			return nil, fmt.Errorf("target %d has no URL", i)
+		}
+
+		tHdr := make(http.Header)
+		for k, v := range hdr {
+			tHdr[k] = append([]string(nil), v...)
+		}
+		for _, h := range t.Headers {
+			tHdr.Add(h.Key, h.Value)
+		}
+
+		tBody := body
+		if t.Body != "" {
+			b, err := base64.StdEncoding.DecodeString(t.Body)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("failed to decode body for target %d", i))
+			}
+			tBody = b
+		}
+
+		tgts = append(tgts, vegeta.Target{
+			Method: t.Method,
+			URL:    t.URL,
+			Header: tHdr,
+			Body:   tBody,
+		})
+	}
+
+	return tgts, nil
+}
@@ -19,6 +19,10 @@ func TestNewAttackOptsFromAttackParams_WithHeaders(t *testing.T) {
 		{"with-headers", models.AttackParams{
 			Rate:     5,
 			Duration: "10s",
+			Target: models.Target{
+				Method: "GET",
+				URL:    "http://localhost:80/",
+			},
 			Headers: []models.AttackHeader{
 				{
 					Key:   "X-Test-Key",
@@ -28,38 +32,152 @@ func TestNewAttackOptsFromAttackParams_WithHeaders(t *testing.T) {
 		}, AttackOpts{
 			Name:     "with-headers",
 			Duration: 10 * time.Second,
-			Rate: vegeta.Rate{
+			Pacer: vegeta.Rate{
 				Freq: 5,
 				Per:  time.Second,
 			},
-			Target: vegeta.Target{
-				Header: http.Header{
-					"X-Test-Key": []string{"test-value"},
+			Targets: []vegeta.Target{
+				{
+					Header: http.Header{
+						"X-Test-Key": []string{"test-value"},
+					},
 				},
 			},
 		}},
 		{"without-headers", models.AttackParams{
 			Rate:     5,
 			Duration: "10s",
+			Target: models.Target{
+				Method: "GET",
+				URL:    "http://localhost:80/",
+			},
 		}, AttackOpts{
 			Name:     "without-headers",
 			Duration: 10 * time.Second,
-			Rate: vegeta.Rate{
+			Pacer: vegeta.Rate{
 				Freq: 5,
 				Per:  time.Second,
 			},
-			Target: vegeta.Target{
-				Header: make(http.Header),
+			Targets: []vegeta.Target{
+				{
+					Header: make(http.Header),
+				},
 			},
 		}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := NewAttackOptsFromAttackParams(tt.name, tt.params)
-			if !reflect.DeepEqual(got.Target.Header, tt.want.Target.Header) {
-				t.Errorf("NewAttackOptsFromAttackParams() = %v, want %v", got.Target.Header, tt.want.Target.Header)
-			} else if err != nil {
-				t.Error(err)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Targets[0].Header, tt.want.Targets[0].Header) {
+				t.Errorf("NewAttackOptsFromAttackParams() = %v, want %v", got.Targets[0].Header, tt.want.Targets[0].Header)
+			}
+		})
+	}
+}
+
+func TestNewAttackOptsFromAttackParams_WithTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  models.AttackParams
+		want    []vegeta.Target
+		wantErr bool
+	}{
+		{
+			name: "multiple targets",
+			params: models.AttackParams{
+				Rate:     5,
+				Duration: "10s",
+				Body:     "Zm9v",
+				Headers: []models.AttackHeader{
+					{Key: "X-Attack", Value: "all"},
+				},
+				Targets: []models.Target{
+					{
+						Method: "GET",
+						URL:    "http://localhost:80/a",
+					},
+					{
+						Method: "POST",
+						URL:    "http://localhost:80/b",
+						Body:   "YmFy",
+						Headers: []models.AttackHeader{
+							{Key: "X-Target", Value: "b"},
+						},
+					},
+				},
+			},
+			want: []vegeta.Target{
+				{
+					Method: "GET",
+					URL:    "http://localhost:80/a",
+					Body:   []byte("foo"),
+					Header: http.Header{
+						"X-Attack": []string{"all"},
+					},
+				},
+				{
+					Method: "POST",
+					URL:    "http://localhost:80/b",
+					Body:   []byte("bar"),
+					Header: http.Header{
+						"X-Attack": []string{"all"},
+						"X-Target": []string{"b"},
+					},
+				},
+			},
+		},
+		{
+			name: "target and targets",
+			params: models.AttackParams{
+				Rate:     5,
+				Duration: "10s",
+				Target: models.Target{
+					URL: "http://localhost:80/a",
+				},
+				Targets: []models.Target{
+					{
+						URL: "http://localhost:80/b",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no target",
+			params: models.AttackParams{
+				Rate:     5,
+				Duration: "10s",
+			},
+			wantErr: true,
+		},
+		{
+			name: "target without URL",
+			params: models.AttackParams{
+				Rate:     5,
+				Duration: "10s",
+				Targets: []models.Target{
+					{
+						Method: "GET",
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAttackOptsFromAttackParams(tt.name, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAttackOptsFromAttackParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.Targets, tt.want) {
+				t.Errorf("NewAttackOptsFromAttackParams() = %v, want %v", got.Targets, tt.want)
 			}
 		})
 	}
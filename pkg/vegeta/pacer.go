@@ -0,0 +1,237 @@
+package vegeta
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+var sineOffsets = map[string]float64{
+	"":          vegeta.MeanUp,
+	"mean-up":   vegeta.MeanUp,
+	"peak":      vegeta.Peak,
+	"mean-down": vegeta.MeanDown,
+	"trough":    vegeta.Trough,
+}
+
+// NewPacer returns the vegeta pacer for the attack rate, and ramp if any.
+// Linear ramps run over the attack duration.
+func NewPacer(rate int, ramp *models.Ramp, dur time.Duration) (vegeta.Pacer, error) {
+	constant := vegeta.Rate{Freq: rate, Per: time.Second}
+	if ramp == nil {
+		return constant, nil
+	}
+
+	if rate <= 0 {
+		return nil, fmt.Errorf("ramp requires a positive rate")
+	}
+
+	switch ramp.Type {
+	case models.RampTypeLinear:
+		if dur <= 0 {
+			return nil, fmt.Errorf("linear ramp requires a duration")
+		}
+		if ramp.To < 0 {
+			return nil, fmt.Errorf("linear ramp rate cannot be negative")
+		}
+		return LinearPacer{From: rate, To: ramp.To, Duration: dur}, nil
+	case models.RampTypeStep:
+		every, err := time.ParseDuration(ramp.Every)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse step ramp interval")
+		}
+		if every <= 0 || ramp.Step <= 0 {
+			return nil, fmt.Errorf("step ramp requires a positive step and interval")
+		}
+		if ramp.To != 0 && ramp.To < rate {
+			return nil, fmt.Errorf("step ramp maximum rate %d is lower than rate %d", ramp.To, rate)
+		}
+		return StepPacer{From: rate, Step: ramp.Step, Every: every, Max: ramp.To}, nil
+	case models.RampTypeSine:
+		period, err := time.ParseDuration(ramp.Period)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse sine ramp period")
+		}
+		if period <= 0 {
+			return nil, fmt.Errorf("sine ramp requires a positive period")
+		}
+		if ramp.Amplitude <= 0 || ramp.Amplitude >= rate {
+			return nil, fmt.Errorf("sine ramp amplitude must be positive and lower than rate %d", rate)
+		}
+		offset, ok := sineOffsets[ramp.Offset]
+		if !ok {
+			return nil, fmt.Errorf("unknown sine ramp offset %q", ramp.Offset)
+		}
+		return vegeta.SinePacer{
+			Period:  period,
+			Mean:    constant,
+			Amp:     vegeta.Rate{Freq: ramp.Amplitude, Per: time.Second},
+			StartAt: offset,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown ramp type %q", ramp.Type)
+	}
+}
+
+// ValidateRamp checks the ramp of the attack params, if any, without
+// running the attack.
+func ValidateRamp(params models.AttackParams) error {
+	if params.Ramp == nil {
+		return nil
+	}
+
+	dur, err := time.ParseDuration(params.Duration)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse duration")
+	}
+
+	_, err = NewPacer(params.Rate, params.Ramp, dur)
+	return err
+}
+
+// LinearPacer paces an attack with a rate changing linearly from From to To
+// hits per second over Duration. The rate stays at To after Duration.
+type LinearPacer struct {
+	From     int
+	To       int
+	Duration time.Duration
+}
+
+// LinearPacer satisfies the Pacer interface.
+var _ vegeta.Pacer = LinearPacer{}
+
+// String returns a pretty-printed description of the LinearPacer's behaviour
+func (lp LinearPacer) String() string {
+	return fmt.Sprintf("Linear{%d -> %d hits/1s over %s}", lp.From, lp.To, lp.Duration)
+}
+
+// Pace determines the length of time to sleep until the next hit is sent.
+func (lp LinearPacer) Pace(elapsed time.Duration, hits uint64) (time.Duration, bool) {
+	if hits < uint64(lp.hits(elapsed)) {
+		// Running behind, send next hit immediately.
+		return 0, false
+	}
+
+	next, ok := lp.timeOf(float64(hits + 1))
+	if !ok {
+		// The rate dropped to zero, no more hits are due.
+		return 0, true
+	}
+
+	return next - elapsed, false
+}
+
+// slope returns the rate change in hits per second, per second.
+func (lp LinearPacer) slope() float64 {
+	return float64(lp.To-lp.From) / lp.Duration.Seconds()
+}
+
+// hits returns the number of hits due after t, integrating the rate:
+//
+//	H = From*t + slope*t²/2, for t <= Duration
+func (lp LinearPacer) hits(t time.Duration) float64 {
+	if t <= 0 {
+		return 0
+	}
+
+	d := lp.Duration.Seconds()
+	s := t.Seconds()
+	if s <= d {
+		return float64(lp.From)*s + lp.slope()*s*s/2
+	}
+
+	return float64(lp.From+lp.To)*d/2 + float64(lp.To)*(s-d)
+}
+
+// timeOf returns the time at which n hits are due, solving the hits equation
+// for t. It returns false if n hits are never due.
+func (lp LinearPacer) timeOf(n float64) (time.Duration, bool) {
+	d := lp.Duration.Seconds()
+	from, to := float64(lp.From), float64(lp.To)
+	atDuration := (from + to) * d / 2
+
+	var s float64
+	switch k := lp.slope(); {
+	case n > atDuration && to == 0:
+		return 0, false
+	case n > atDuration:
+		s = d + (n-atDuration)/to
+	case k == 0:
+		s = n / from
+	default:
+		s = (math.Sqrt(from*from+2*k*n) - from) / k
+	}
+
+	return time.Duration(s * float64(time.Second)), true
+}
+
+// StepPacer paces an attack with a rate starting at From hits per second,
+// increased by Step hits per second Every interval, up to Max if set.
+type StepPacer struct {
+	From  int
+	Step  int
+	Every time.Duration
+	Max   int
+}
+
+// StepPacer satisfies the Pacer interface.
+var _ vegeta.Pacer = StepPacer{}
+
+// String returns a pretty-printed description of the StepPacer's behaviour
+func (sp StepPacer) String() string {
+	return fmt.Sprintf("Step{%d hits/1s + %d every %s, max %d}", sp.From, sp.Step, sp.Every, sp.Max)
+}
+
+// Pace determines the length of time to sleep until the next hit is sent.
+func (sp StepPacer) Pace(elapsed time.Duration, hits uint64) (time.Duration, bool) {
+	expected := sp.hits(elapsed)
+	if hits < uint64(expected) {
+		// Running behind, send next hit immediately.
+		return 0, false
+	}
+
+	// The rate is constant within a step. Crossing into the next step only
+	// delays a single hit by a fraction of the interval.
+	rate := sp.rate(int64(elapsed / sp.Every))
+	wait := (float64(hits+1) - expected) / rate
+
+	return time.Duration(wait * float64(time.Second)), false
+}
+
+// uncapped returns the number of steps before the rate reaches Max.
+func (sp StepPacer) uncapped() int64 {
+	if sp.Max == 0 {
+		return math.MaxInt64
+	}
+	return int64(math.Ceil(float64(sp.Max-sp.From) / float64(sp.Step)))
+}
+
+// rate returns the rate in hits per second of the ith step.
+func (sp StepPacer) rate(i int64) float64 {
+	if i >= sp.uncapped() {
+		return float64(sp.Max)
+	}
+	return float64(sp.From) + float64(i)*float64(sp.Step)
+}
+
+// hits returns the number of hits due after t, summing the rates of the
+// completed steps and adding the hits due in the current step.
+func (sp StepPacer) hits(t time.Duration) float64 {
+	if t <= 0 {
+		return 0
+	}
+
+	// Sum the rates of the n completed steps, the first m of them uncapped
+	n := int64(t / sp.Every)
+	m := n
+	if u := sp.uncapped(); u < n {
+		m = u
+	}
+	completed := float64(m)*float64(sp.From) + float64(sp.Step)*float64(m)*float64(m-1)/2 + float64(n-m)*float64(sp.Max)
+
+	return sp.Every.Seconds()*completed + sp.rate(n)*(t-time.Duration(n)*sp.Every).Seconds()
+}
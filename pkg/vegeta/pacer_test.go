@@ -0,0 +1,150 @@
+package vegeta
+
+import (
+	"math"
+	"testing"
+	"time"
+	"vegeta-server/models"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func TestNewPacer(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    int
+		ramp    *models.Ramp
+		want    vegeta.Pacer
+		wantErr bool
+	}{
+		{
+			name: "constant",
+			rate: 5,
+			want: vegeta.Rate{Freq: 5, Per: time.Second},
+		},
+		{
+			name: "linear",
+			rate: 5,
+			ramp: &models.Ramp{Type: models.RampTypeLinear, To: 50},
+			want: LinearPacer{From: 5, To: 50, Duration: 10 * time.Second},
+		},
+		{
+			name: "step",
+			rate: 5,
+			ramp: &models.Ramp{Type: models.RampTypeStep, Step: 5, Every: "2s", To: 20},
+			want: StepPacer{From: 5, Step: 5, Every: 2 * time.Second, Max: 20},
+		},
+		{
+			name: "sine",
+			rate: 50,
+			ramp: &models.Ramp{Type: models.RampTypeSine, Amplitude: 10, Period: "1m", Offset: "peak"},
+			want: vegeta.SinePacer{
+				Period:  time.Minute,
+				Mean:    vegeta.Rate{Freq: 50, Per: time.Second},
+				Amp:     vegeta.Rate{Freq: 10, Per: time.Second},
+				StartAt: vegeta.Peak,
+			},
+		},
+		{
+			name:    "unknown type",
+			rate:    5,
+			ramp:    &models.Ramp{Type: "exponential"},
+			wantErr: true,
+		},
+		{
+			name:    "step without interval",
+			rate:    5,
+			ramp:    &models.Ramp{Type: models.RampTypeStep, Step: 5},
+			wantErr: true,
+		},
+		{
+			name:    "step maximum below rate",
+			rate:    5,
+			ramp:    &models.Ramp{Type: models.RampTypeStep, Step: 5, Every: "1s", To: 1},
+			wantErr: true,
+		},
+		{
+			name:    "sine amplitude above rate",
+			rate:    5,
+			ramp:    &models.Ramp{Type: models.RampTypeSine, Amplitude: 10, Period: "1m"},
+			wantErr: true,
+		},
+		{
+			name:    "sine unknown offset",
+			rate:    50,
+			ramp:    &models.Ramp{Type: models.RampTypeSine, Amplitude: 10, Period: "1m", Offset: "top"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPacer(tt.rate, tt.ramp, 10*time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPacer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NewPacer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// paceHits simulates an attack with the pacer, and returns the number of hits
+// sent during du.
+func paceHits(p vegeta.Pacer, du time.Duration) uint64 {
+	var elapsed time.Duration
+	var hits uint64
+	for {
+		wait, stop := p.Pace(elapsed, hits)
+		if stop || elapsed+wait >= du {
+			return hits
+		}
+		elapsed += wait
+		hits++
+	}
+}
+
+func TestLinearPacer_Pace(t *testing.T) {
+	tests := []struct {
+		name  string
+		pacer LinearPacer
+		du    time.Duration
+		want  float64
+	}{
+		{"up", LinearPacer{From: 10, To: 100, Duration: 10 * time.Second}, 10 * time.Second, 550},
+		{"down", LinearPacer{From: 100, To: 10, Duration: 10 * time.Second}, 10 * time.Second, 550},
+		{"flat", LinearPacer{From: 10, To: 10, Duration: 10 * time.Second}, 10 * time.Second, 100},
+		{"past duration", LinearPacer{From: 10, To: 20, Duration: 10 * time.Second}, 15 * time.Second, 250},
+		{"down to zero", LinearPacer{From: 10, To: 0, Duration: 10 * time.Second}, 20 * time.Second, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paceHits(tt.pacer, tt.du); math.Abs(float64(got)-tt.want) > 1 {
+				t.Errorf("LinearPacer hits = %d, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepPacer_Pace(t *testing.T) {
+	tests := []struct {
+		name  string
+		pacer StepPacer
+		du    time.Duration
+		want  float64
+	}{
+		// 10 + 20 + 30 + 40 + 50 hits/s for 1s each
+		{"uncapped", StepPacer{From: 10, Step: 10, Every: time.Second}, 5 * time.Second, 150},
+		// 10 + 20 + 25 + 25 + 25 hits/s for 1s each
+		{"capped", StepPacer{From: 10, Step: 10, Every: time.Second, Max: 25}, 5 * time.Second, 105},
+		// 10 hits/s for 2s, then 15 hits/s for 1s
+		{"mid step", StepPacer{From: 10, Step: 5, Every: 2 * time.Second}, 3 * time.Second, 35},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paceHits(tt.pacer, tt.du); math.Abs(float64(got)-tt.want) > 1 {
+				t.Errorf("StepPacer hits = %d, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package vegeta
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+// ParsePercentiles parses the comma separated list of latency percentiles
+// requested along with JSON and text reports, e.g. "99.9,99.99"
+func ParsePercentiles(s string) ([]float64, error) {
+	percentiles := make([]float64, 0)
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+
+		percentile, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to parse percentile %s", p))
+		}
+		if percentile <= 0 || percentile > 100 {
+			return nil, fmt.Errorf("percentile %s must be in (0, 100]", p)
+		}
+		percentiles = append(percentiles, percentile)
+	}
+	return percentiles, nil
+}
+
+// formatPercentile formats a percentile the way it was requested, without
+// trailing zeros
+func formatPercentile(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// latencyStats tracks the minimum latency of the results, which the metrics
+// of the vegeta lib do not compute
+type latencyStats struct {
+	min time.Duration
+	n   uint64
+}
+
+func (l *latencyStats) Add(r *vegeta.Result) {
+	if l.n == 0 || r.Latency < l.min {
+		l.min = r.Latency
+	}
+	l.n++
+}
+
+// jsonReport returns the JSON report of the closed metrics, with the latencies
+// of the requested percentiles
+func jsonReport(id string, m *vegeta.Metrics, l latencyStats, percentiles []float64) models.JSONReportResponse {
+	resp := models.JSONReportResponse{
+		ID:          id,
+		StatusCodes: make(map[string]int),
+		Errors:      make([]string, 0),
+	}
+
+	// Closed metrics without any results have NaN means and ratios
+	if m.Requests == 0 {
+		return resp
+	}
+
+	resp.Latencies.Total = int64(m.Latencies.Total)
+	resp.Latencies.Mean = int64(m.Latencies.Mean)
+	resp.Latencies.Min = int64(l.min)
+	resp.Latencies.Max = int64(m.Latencies.Max)
+	resp.Latencies.P50th = int64(m.Latencies.P50)
+	resp.Latencies.P90th = int64(m.Latencies.Quantile(0.90))
+	resp.Latencies.P95th = int64(m.Latencies.P95)
+	resp.Latencies.P99th = int64(m.Latencies.P99)
+	if len(percentiles) > 0 {
+		resp.Latencies.Percentiles = make(map[string]int64, len(percentiles))
+		for _, p := range percentiles {
+			resp.Latencies.Percentiles[formatPercentile(p)+"th"] = int64(m.Latencies.Quantile(p / 100))
+		}
+	}
+
+	resp.BytesIn.Total = m.BytesIn.Total
+	resp.BytesIn.Mean = m.BytesIn.Mean
+	resp.BytesOut.Total = m.BytesOut.Total
+	resp.BytesOut.Mean = m.BytesOut.Mean
+	resp.Earliest = m.Earliest.Format(time.RFC3339Nano)
+	resp.Latest = m.Latest.Format(time.RFC3339Nano)
+	resp.End = m.End.Format(time.RFC3339Nano)
+	resp.Duration = int64(m.Duration)
+	resp.Wait = int64(m.Wait)
+	resp.Requests = m.Requests
+	resp.Rate = m.Rate
+	resp.Throughput = m.Throughput
+	resp.Success = m.Success
+
+	for code, count := range m.StatusCodes {
+		resp.StatusCodes[code] = count
+	}
+	resp.Errors = append(resp.Errors, m.Errors...)
+
+	return resp
+}
+
+// addPercentiles inserts the latencies of the requested percentiles after the
+// latencies line of text reports, aligned with its values
+func addPercentiles(report []byte, m *vegeta.Metrics, percentiles []float64) []byte {
+	if len(percentiles) == 0 {
+		return report
+	}
+
+	start := bytes.Index(report, []byte("Latencies"))
+	if start < 0 {
+		return report
+	}
+	end := start + bytes.IndexByte(report[start:], '\n') + 1
+	if end <= start {
+		return report
+	}
+
+	// The values start after the padding following the bracketed names
+	line := report[start:end]
+	col := bytes.IndexByte(line, ']') + 1
+	col += len(line[col:]) - len(bytes.TrimLeft(line[col:], " "))
+
+	names := make([]string, 0, len(percentiles))
+	values := make([]string, 0, len(percentiles))
+	for _, p := range percentiles {
+		names = append(names, formatPercentile(p))
+		values = append(values, m.Latencies.Quantile(p/100).String())
+	}
+
+	header := fmt.Sprintf("%-14s[%s]", "Percentiles", strings.Join(names, ", "))
+	info := fmt.Sprintf("%-*s%s\n", col, header+"  ", strings.Join(values, ", "))
+
+	return append(append(append([]byte{}, report[:end]...), info...), report[end:]...)
+}
@@ -0,0 +1,116 @@
+package vegeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"vegeta-server/models"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func TestParsePercentiles(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []float64
+		wantErr bool
+	}{
+		{"", []float64{}, false},
+		{"99.9, 99.99", []float64{99.9, 99.99}, false},
+		{"100,", []float64{100}, false},
+		{"0", nil, true},
+		{"100.1", nil, true},
+		{"p99", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParsePercentiles(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePercentiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePercentiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// latencyResults encodes a result per latency, in milliseconds
+func latencyResults(t *testing.T, latencies ...int) *bytes.Buffer {
+	var buf bytes.Buffer
+	enc := vegeta.NewEncoder(&buf)
+	t0 := time.Date(2019, 3, 2, 22, 46, 47, 0, time.UTC)
+	for i, latency := range latencies {
+		r := vegeta.Result{
+			Code:      200,
+			Timestamp: t0.Add(time.Duration(i) * time.Second),
+			Latency:   time.Duration(latency) * time.Millisecond,
+			BytesIn:   uint64(i),
+		}
+		if err := enc.Encode(&r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &buf
+}
+
+func TestCreateReportFromReader_Percentiles(t *testing.T) {
+	format := NewJSONFormat()
+	format.SetMeta("percentiles", "99.9,100")
+	b, err := CreateReportFromReader(latencyResults(t, 30, 10, 20, 40), "id", format)
+	if err != nil {
+		t.Fatalf("CreateReportFromReader() error = %v", err)
+	}
+
+	var got models.JSONReportResponse
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Latencies.Min != int64(10*time.Millisecond) || got.Latencies.Max != int64(40*time.Millisecond) {
+		t.Errorf("latencies = %+v, want min 10ms and max 40ms", got.Latencies)
+	}
+	if got.Latencies.P90th == 0 || got.Latencies.P90th > got.Latencies.Max {
+		t.Errorf("90th latency = %d, want up to the max latency", got.Latencies.P90th)
+	}
+	if want := map[string]int64{"99.9th": int64(40 * time.Millisecond), "100th": int64(40 * time.Millisecond)}; !reflect.DeepEqual(got.Latencies.Percentiles, want) {
+		t.Errorf("percentiles = %v, want %v", got.Latencies.Percentiles, want)
+	}
+
+	// Means are no longer truncated
+	if got.BytesIn.Total != 6 || got.BytesIn.Mean != 1.5 || got.Throughput == 0 {
+		t.Errorf("CreateReportFromReader() = %+v", got)
+	}
+
+	text := NewTextFormat()
+	text.SetMeta("percentiles", "99.9")
+	b, err = CreateReportFromReader(latencyResults(t, 30, 10, 20, 40), "id", text)
+	if err != nil {
+		t.Fatalf("CreateReportFromReader() error = %v", err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if len(lines) < 5 || !strings.HasPrefix(lines[4], "Percentiles   [99.9]") || !strings.HasSuffix(lines[4], " 40ms") {
+		t.Errorf("CreateReportFromReader() = %s, want the percentiles after the latencies", b)
+	}
+	if col := strings.Index(lines[3], "25ms"); col < 0 || strings.Index(lines[4], "40ms") != col {
+		t.Errorf("CreateReportFromReader() = %s, want the percentiles aligned with the latencies", b)
+	}
+}
+
+func TestCreateReportFromReader_HDRPlot(t *testing.T) {
+	b, err := CreateReportFromReader(latencyResults(t, 10, 20), "id", NewHDRPlotFormat())
+	if err != nil {
+		t.Fatalf("CreateReportFromReader() error = %v", err)
+	}
+
+	// The report is the plain `vegeta report -type=hdrplot` output, without ID
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if !strings.HasPrefix(lines[0], "Value(ms)") {
+		t.Errorf("CreateReportFromReader() header = %s", lines[0])
+	}
+	if last := strings.Fields(lines[len(lines)-1]); last[0] != "20.000000" || last[1] != "1.000000" || last[2] != "2" {
+		t.Errorf("CreateReportFromReader() last line = %v", last)
+	}
+}
@@ -0,0 +1,94 @@
+package vegeta
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	vegeta "github.com/tsenart/vegeta/lib"
+	"github.com/tsenart/vegeta/lib/plot"
+)
+
+// ParseThreshold parses the downsampling threshold of plot reports, which is
+// the number of points series are downsampled to. Zero disables downsampling.
+func ParseThreshold(s string) (int, error) {
+	threshold, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("failed to parse threshold %s", s))
+	}
+	// LTTB keeps the first and last points, and at least one in between
+	if threshold < 0 || threshold > 0 && threshold < 3 {
+		return 0, fmt.Errorf("threshold %s must be 0 or at least 3", s)
+	}
+	return threshold, nil
+}
+
+// plotReport renders the decoded results as the HTML latency plot of `vegeta
+// plot`. The results of each attack are overlaid as separate series.
+func plotReport(dec vegeta.Decoder, id string, format Format) ([]byte, error) {
+	meta := format.Meta()
+
+	threshold, err := ParseThreshold(meta["threshold"])
+	if err != nil {
+		return nil, err
+	}
+
+	title := meta["title"]
+	if title == "" {
+		title = "Vegeta Plot: " + id
+	}
+
+	// The plot requires the results of each attack in sequence, with
+	// increasing timestamps, which merged results are not. The results are
+	// sorted by timestamp and numbered again.
+	attacks := make(map[string][]vegeta.Result)
+	for {
+		var r vegeta.Result
+		if err = dec.Decode(&r); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "failed to decode result")
+		}
+
+		// Only the fields plotted are kept
+		attacks[r.Attack] = append(attacks[r.Attack], vegeta.Result{
+			Attack:    r.Attack,
+			Timestamp: r.Timestamp,
+			Latency:   r.Latency,
+			Error:     r.Error,
+		})
+	}
+
+	// The attacks are added in order of their IDs, so that the plot does not
+	// depend on the iteration order of the map
+	ids := make([]string, 0, len(attacks))
+	for id := range attacks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	p := plot.New(plot.Title(title), plot.Downsample(threshold))
+	for _, id := range ids {
+		results := attacks[id]
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Timestamp.Before(results[j].Timestamp)
+		})
+		for i := range results {
+			results[i].Seq = uint64(i)
+			if err = p.Add(&results[i]); err != nil {
+				return nil, errors.Wrap(err, "failed to plot result")
+			}
+		}
+	}
+	p.Close()
+
+	var buf bytes.Buffer
+	if _, err = p.WriteTo(&buf); err != nil {
+		return nil, errors.Wrap(err, "failed to render plot")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,90 @@
+package vegeta
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    int
+		wantErr bool
+	}{
+		{"4000", 4000, false},
+		{"0", 0, false},
+		{"3", 3, false},
+		{"2", 0, true},
+		{"-1", 0, true},
+		{"many", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseThreshold(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateReportFromReaders_Plot(t *testing.T) {
+	t0 := time.Date(2019, 3, 2, 22, 46, 47, 0, time.UTC)
+
+	// Merged results are out of order, with colliding sequence numbers
+	encode := func(attack string, offsets ...time.Duration) *bytes.Buffer {
+		var buf bytes.Buffer
+		enc := vegeta.NewEncoder(&buf)
+		for _, offset := range offsets {
+			r := vegeta.Result{Attack: attack, Code: 200, Timestamp: t0.Add(offset), Latency: time.Millisecond}
+			if err := enc.Encode(&r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return &buf
+	}
+	readers := []io.Reader{
+		encode("1", time.Second, 0, 2*time.Second),
+		encode("2", 0, time.Second),
+	}
+
+	format := NewPlotFormat()
+	format.SetMeta("threshold", DefaultThresholdString)
+	b, err := CreateReportFromReaders(readers, "1,2", format)
+	if err != nil {
+		t.Fatalf("CreateReportFromReaders() error = %v", err)
+	}
+
+	html := string(b)
+	for _, want := range []string{"<title>Vegeta Plot: 1,2</title>", `"1: OK"`, `"2: OK"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("CreateReportFromReaders() plot does not contain %s", want)
+		}
+	}
+
+	// The attacks are overlaid in the same order whatever the order of the
+	// results
+	reversed, err := CreateReportFromReaders([]io.Reader{
+		encode("2", 0, time.Second),
+		encode("1", time.Second, 0, 2*time.Second),
+	}, "1,2", format)
+	if err != nil {
+		t.Fatalf("CreateReportFromReaders() error = %v", err)
+	}
+	if !bytes.Equal(reversed, b) {
+		t.Error("CreateReportFromReaders() plot depends on the order of the results")
+	}
+
+	format.SetMeta("threshold", "")
+	if _, err = CreateReportFromReaders(readers, "1,2", format); err == nil {
+		t.Error("CreateReportFromReaders() without a threshold succeeded")
+	}
+}
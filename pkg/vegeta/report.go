@@ -20,14 +20,50 @@ const (
 	HistogramFormatString string = "histogram"
 	// BinaryFormatString typedef for query param "binary"
 	BinaryFormatString string = "binary"
+	// CSVFormatString typedef for query param "csv"
+	CSVFormatString string = "csv"
+	// JSONLinesFormatString typedef for query param "jsonl"
+	JSONLinesFormatString string = "jsonl"
+	// TimeseriesFormatString typedef for query param "timeseries"
+	TimeseriesFormatString string = "timeseries"
+	// PlotFormatString typedef for query param "plot"
+	PlotFormatString string = "plot"
+	// HDRPlotFormatString typedef for query param "hdrplot"
+	HDRPlotFormatString string = "hdrplot"
 	// DefaultBucketString Default Bucket String
 	DefaultBucketString string = "0,500ms,1s,1.5s,2s,2.5s,3s"
+	// DefaultIntervalString Default Interval String of time series reports
+	DefaultIntervalString string = "1s"
+	// DefaultThresholdString Default downsampling threshold of plot reports,
+	// the same as `vegeta plot`
+	DefaultThresholdString string = "4000"
 )
 
 // CreateReportFromReader takes in an io.Reader with the vegeta gob, encoded result and
-// returns the decoded result as a byte array
+// returns the decoded result as a byte array. The CSV and JSON-lines formats
+// return the raw results re-encoded, like `vegeta encode`.
 func CreateReportFromReader(reader io.Reader, id string, format Format) ([]byte, error) {
-	dec := vegeta.DecoderFor(reader)
+	return CreateReportFromReaders([]io.Reader{reader}, id, format)
+}
+
+// CreateReportFromReaders decodes the results of several attacks into a single
+// report, the same way `vegeta report` merges several result files. The
+// binary, CSV and JSON-lines formats return the merged raw results.
+func CreateReportFromReaders(readers []io.Reader, id string, format Format) ([]byte, error) {
+	dec := decoderFor(readers)
+
+	switch format.String() {
+	case BinaryFormatString, CSVFormatString, JSONLinesFormatString:
+		var buf bytes.Buffer
+		if err := encodeResults(dec, &buf, format); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case TimeseriesFormatString:
+		return timeseries(dec, id, format)
+	case PlotFormatString:
+		return plotReport(dec, id, format)
+	}
 
 	m := vegeta.Metrics{}
 
@@ -37,12 +73,20 @@ func CreateReportFromReader(reader io.Reader, id string, format Format) ([]byte,
 
 	fs := format.String()
 
+	// Percentiles requested along with JSON and text reports
+	percentiles, err := ParsePercentiles(format.Meta()["percentiles"])
+	if err != nil {
+		return nil, err
+	}
+	var latencies latencyStats
+
 	switch fs {
 	case JSONFormatString:
-		// Create a new reporter with the metrics
-		rep = vegeta.NewJSONReporter(&m)
+		// The JSON report is created from the metrics once closed
 	case TextFormatString:
 		rep = vegeta.NewTextReporter(&m)
+	case HDRPlotFormatString:
+		rep = vegeta.NewHDRHistogramPlotReporter(&m)
 	case HistogramFormatString:
 		var hist vegeta.Histogram
 		meta := format.Meta()
@@ -69,35 +113,128 @@ decode:
 		}
 
 		report.Add(&r)
+		latencies.Add(&r)
 	}
 	if rc != nil {
 		rc.Close()
 	}
 
+	if fs == JSONFormatString {
+		return json.Marshal(jsonReport(id, &m, latencies, percentiles))
+	}
+
 	var b []byte
 	buf := bytes.NewBuffer(b)
-	err := rep.Report(buf)
+	err = rep.Report(buf)
 	if err != nil {
 		return nil, errors.Wrap(err, "reporter failed")
 	}
 
 	// Add ID to the report
 	switch fs {
+	case TextFormatString:
+		return addPercentiles(addID(buf, id), &m, percentiles), nil
+	case HistogramFormatString:
+		return addID(buf, id), nil
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decoderFor returns a decoder of the results of all readers, in a round-robin
+// fashion. Readers without results are skipped.
+func decoderFor(readers []io.Reader) vegeta.Decoder {
+	decs := make([]vegeta.Decoder, 0, len(readers))
+	for _, r := range readers {
+		if dec := vegeta.DecoderFor(r); dec != nil {
+			decs = append(decs, dec)
+		}
+	}
+
+	if len(decs) == 0 {
+		return func(*vegeta.Result) error { return io.EOF }
+	}
+	return vegeta.NewRoundRobinDecoder(decs...)
+}
+
+// WriteResults writes the merged results of several attacks to the writer in
+// the binary, CSV or JSON-lines format, as they are decoded
+func WriteResults(readers []io.Reader, w io.Writer, format Format) error {
+	return encodeResults(decoderFor(readers), w, format)
+}
+
+// encodeResults re-encodes the decoded results to the writer in the binary,
+// CSV or JSON-lines format
+func encodeResults(dec vegeta.Decoder, w io.Writer, format Format) error {
+	var enc vegeta.Encoder
+	switch format.String() {
+	case BinaryFormatString:
+		enc = vegeta.NewEncoder(w)
+	case CSVFormatString:
+		enc = vegeta.NewCSVEncoder(w)
+	case JSONLinesFormatString:
+		enc = vegeta.NewJSONEncoder(w)
+	default:
+		return fmt.Errorf("format %s not supported", format)
+	}
+
+	for {
+		var r vegeta.Result
+		err := dec.Decode(&r)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return errors.Wrap(err, "failed to decode result")
+		}
+
+		if err = enc.Encode(&r); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to encode result to %s", format))
+		}
+	}
+
+	return nil
+}
+
+func addID(report *bytes.Buffer, id string) []byte {
+	return append([]byte(fmt.Sprintf("ID %s\n", id)), report.Bytes()...)
+}
+
+// AddLabels adds the labels and description of an attack to its report. Text
+// and histogram reports list them after the attack ID, binary reports are
+// returned as is.
+func AddLabels(report []byte, params models.AttackParams, format Format) ([]byte, error) {
+	if len(params.Labels) == 0 && params.Description == "" {
+		return report, nil
+	}
+
+	switch format.String() {
 	case JSONFormatString:
 		var jsonReportResponse models.JSONReportResponse
-		err = json.Unmarshal(buf.Bytes(), &jsonReportResponse)
+		err := json.Unmarshal(report, &jsonReportResponse)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to unmarshal JSONReportResponse")
 		}
-		jsonReportResponse.ID = id
+		jsonReportResponse.Labels = params.Labels
+		jsonReportResponse.Description = params.Description
 		return json.Marshal(jsonReportResponse)
 	case TextFormatString, HistogramFormatString:
-		return addID(buf, id), nil
+		return addLabels(report, params), nil
 	}
 
-	return buf.Bytes(), nil
+	return report, nil
 }
 
-func addID(report *bytes.Buffer, id string) []byte {
-	return append([]byte(fmt.Sprintf("ID %s\n", id)), report.Bytes()...)
+// addLabels inserts the labels and description after the ID line of text reports
+func addLabels(report []byte, params models.AttackParams) []byte {
+	var info bytes.Buffer
+	if len(params.Labels) > 0 {
+		fmt.Fprintf(&info, "Labels %s\n", models.FormatLabels(params.Labels))
+	}
+	if params.Description != "" {
+		fmt.Fprintf(&info, "Description %s\n", params.Description)
+	}
+
+	i := bytes.IndexByte(report, '\n') + 1
+	return append(append(append([]byte{}, report[:i]...), info.Bytes()...), report[i:]...)
 }
@@ -2,10 +2,15 @@ package vegeta
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
 	"testing"
+	"time"
+	"vegeta-server/models"
+
+	vegeta "github.com/tsenart/vegeta/lib"
 )
 
 func Test_addID(t *testing.T) {
@@ -31,6 +36,60 @@ func Test_addID(t *testing.T) {
 	}
 }
 
+func TestAddLabels(t *testing.T) {
+	params := models.AttackParams{
+		Labels:      map[string]string{"team": "payments", "env": "staging"},
+		Description: "checkout load test",
+	}
+
+	tests := []struct {
+		name   string
+		report []byte
+		params models.AttackParams
+		format Format
+		want   []byte
+	}{
+		{
+			name:   "No labels",
+			report: []byte("ID 123\ntest string"),
+			format: NewTextFormat(),
+			want:   []byte("ID 123\ntest string"),
+		},
+		{
+			name:   "Text",
+			report: []byte("ID 123\ntest string"),
+			params: params,
+			format: NewTextFormat(),
+			want:   []byte("ID 123\nLabels env=staging,team=payments\nDescription checkout load test\ntest string"),
+		},
+		{
+			name:   "JSON",
+			report: []byte(`{"id":"123"}`),
+			params: models.AttackParams{Labels: map[string]string{"team": "payments"}},
+			format: NewJSONFormat(),
+			want:   []byte(`{"id":"123","labels":{"team":"payments"},`),
+		},
+		{
+			name:   "Binary",
+			report: []byte("binary"),
+			params: params,
+			format: NewBinaryFormat(),
+			want:   []byte("binary"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AddLabels(tt.report, tt.params, tt.format)
+			if err != nil {
+				t.Fatalf("AddLabels() error = %v", err)
+			}
+			if !bytes.HasPrefix(got, tt.want) {
+				t.Errorf("AddLabels() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCreateReportFromReader(t *testing.T) {
 	type args struct {
 		reader io.Reader
@@ -68,7 +127,7 @@ func TestCreateReportFromReader(t *testing.T) {
 				format: NewJSONFormat(),
 			},
 			want: want{
-				byt: []byte(`{"id":"id","latencies":{"total":6484537567,"mean":6484537567,"max":6484537567,"50th":6484537567,"95th":6484537567,"99th":6484537567},"bytes_in":{"total":0,"mean":0},"bytes_out":{"total":0,"mean":0},"earliest":"2019-03-02T22:46:47.969175+05:30","latest":"2019-03-02T22:46:47.969175+05:30","end":"2019-03-02T22:46:54.453712567+05:30","duration":0,"wait":6484537567,"requests":1,"rate":1,"success":1,"status_codes":{"200":1},"errors":[]}`), // nolint: lll
+				byt: []byte(`{"id":"id","latencies":{"total":6484537567,"mean":6484537567,"min":6484537567,"max":6484537567,"50th":6484537567,"90th":6484537567,"95th":6484537567,"99th":6484537567},"bytes_in":{"total":0,"mean":0},"bytes_out":{"total":0,"mean":0},"earliest":"2019-03-02T22:46:47.969175+05:30","latest":"2019-03-02T22:46:47.969175+05:30","end":"2019-03-02T22:46:54.453712567+05:30","duration":0,"wait":6484537567,"requests":1,"rate":1,"throughput":1,"success":1,"status_codes":{"200":1},"errors":[]}`), // nolint: lll
 				err: nil,
 			},
 		},
@@ -127,3 +186,118 @@ Bucket           #  %        Histogram
 		})
 	}
 }
+
+func TestCreateReportFromReader_Encodings(t *testing.T) {
+	results := []vegeta.Result{
+		{
+			Attack:    "id",
+			Seq:       0,
+			Code:      200,
+			Timestamp: time.Unix(1551547007, 969175000).UTC(),
+			Latency:   10 * time.Millisecond,
+			BytesIn:   12,
+			Body:      []byte("hello, world"),
+		},
+		{
+			Attack:    "id",
+			Seq:       1,
+			Timestamp: time.Unix(1551547008, 0).UTC(),
+			Latency:   time.Second,
+			Error:     "timeout",
+		},
+	}
+
+	var gob bytes.Buffer
+	enc := vegeta.NewEncoder(&gob)
+	for i := range results {
+		if err := enc.Encode(&results[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		dec    func(io.Reader) vegeta.Decoder
+	}{
+		{"CSV", NewCSVFormat(), vegeta.NewCSVDecoder},
+		{"JSON-lines", NewJSONLinesFormat(), vegeta.NewJSONDecoder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := CreateReportFromReader(bytes.NewReader(gob.Bytes()), "id", tt.format)
+			if err != nil {
+				t.Fatalf("CreateReportFromReader() error = %v", err)
+			}
+
+			// The encoded results decode back to the original results
+			dec := tt.dec(bytes.NewReader(b))
+			for i := range results {
+				var got vegeta.Result
+				if err := dec.Decode(&got); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				got.Timestamp = got.Timestamp.UTC()
+				if !got.Equal(results[i]) {
+					t.Errorf("result %d = %v, want %v", i, got, results[i])
+				}
+			}
+			if err := dec.Decode(&vegeta.Result{}); err != io.EOF {
+				t.Errorf("Decode() error = %v, want EOF", err)
+			}
+		})
+	}
+}
+
+func TestWriteResults(t *testing.T) {
+	streams := make([]io.Reader, 0)
+	for _, codes := range [][]uint16{{200, 200}, {500}} {
+		var buf bytes.Buffer
+		enc := vegeta.NewEncoder(&buf)
+		for _, code := range codes {
+			if err := enc.Encode(&vegeta.Result{Code: code, Timestamp: time.Now()}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		streams = append(streams, &buf)
+	}
+
+	var w bytes.Buffer
+	if err := WriteResults(streams, &w, NewJSONLinesFormat()); err != nil {
+		t.Fatalf("WriteResults() error = %v", err)
+	}
+	if got := bytes.Count(w.Bytes(), []byte("\n")); got != 3 {
+		t.Errorf("WriteResults() wrote %d results, want 3", got)
+	}
+
+	if err := WriteResults(nil, &w, NewTextFormat()); err == nil {
+		t.Error("WriteResults() want error for the text format")
+	}
+}
+
+func TestCreateReportFromReaders(t *testing.T) {
+	streams := make([]io.Reader, 0)
+	for _, codes := range [][]uint16{{200, 200}, {500}, {}} {
+		var buf bytes.Buffer
+		enc := vegeta.NewEncoder(&buf)
+		for _, code := range codes {
+			if err := enc.Encode(&vegeta.Result{Code: code, Timestamp: time.Now(), Latency: time.Millisecond}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		streams = append(streams, &buf)
+	}
+
+	b, err := CreateReportFromReaders(streams, "1,2,3", NewJSONFormat())
+	if err != nil {
+		t.Fatalf("CreateReportFromReaders() error = %v", err)
+	}
+
+	var got models.JSONReportResponse
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "1,2,3" || got.Requests != 3 || got.StatusCodes["200"] != 2 || got.StatusCodes["500"] != 1 {
+		t.Errorf("CreateReportFromReaders() = %+v, want the 3 merged results", got)
+	}
+}
@@ -0,0 +1,145 @@
+package vegeta
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+// SpoolDir is the directory the results of attacks are spooled to
+var SpoolDir = os.TempDir()
+
+// spoolBufferSize is the size of the buffer results are written to the spool
+// file through
+const spoolBufferSize = 64 * 1024
+
+// Spool is an append-only file the encoded results of an attack are written
+// to, so that memory use does not grow with the size of the attack. Once
+// closed, the spool reads the results back from the file.
+type Spool struct {
+	f *os.File
+	w *bufio.Writer
+	r io.Reader
+}
+
+// NewSpool creates a spool file in SpoolDir for the results of an attack
+func NewSpool(id string) (*Spool, error) {
+	f, err := ioutil.TempFile(SpoolDir, "vegeta-"+id+"-*.results")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spool file")
+	}
+
+	return &Spool{
+		f,
+		bufio.NewWriterSize(f, spoolBufferSize),
+		nil,
+	}, nil
+}
+
+// Write appends to the spool file
+func (s *Spool) Write(p []byte) (int, error) {
+	return s.w.Write(p)
+}
+
+// Close flushes and closes the spool file, which is kept until removed
+func (s *Spool) Close() error {
+	if err := s.w.Flush(); err != nil {
+		_ = s.f.Close()
+		return errors.Wrap(err, "failed to flush spool file")
+	}
+	if err := s.f.Close(); err != nil {
+		return errors.Wrap(err, "failed to close spool file")
+	}
+
+	s.r = SpoolReader(s.f.Name())
+	return nil
+}
+
+// Discard closes and removes the spool file
+func (s *Spool) Discard() error {
+	_ = s.f.Close()
+	return os.Remove(s.f.Name())
+}
+
+// Path returns the path of the spool file
+func (s *Spool) Path() string {
+	return s.f.Name()
+}
+
+// Read the spool file from the start, once the spool is closed
+func (s *Spool) Read(p []byte) (int, error) {
+	if s.r == nil {
+		return 0, errors.New("cannot read spool before it is closed")
+	}
+	return s.r.Read(p)
+}
+
+// SpoolReader returns a reader of the spool file at the path. The file is only
+// opened on the first read, and closed once read to the end.
+func SpoolReader(path string) io.Reader {
+	return &spoolReader{path: path}
+}
+
+type spoolReader struct {
+	path string
+	f    *os.File
+	err  error
+}
+
+func (r *spoolReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	if r.f == nil {
+		if r.f, r.err = os.Open(r.path); r.err != nil {
+			return 0, r.err
+		}
+	}
+
+	n, err := r.f.Read(p)
+	if err != nil {
+		_ = r.f.Close()
+		r.err = err
+	}
+	return n, err
+}
+
+// Merge decodes several results and spools them as the results of a single
+// attack, the same way `vegeta report` merges several result files. Empty
+// results are skipped.
+func Merge(id string, results ...io.Reader) (*Spool, error) {
+	spool, err := NewSpool(id)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := decoderFor(results)
+	enc := vegeta.NewEncoder(spool)
+	for {
+		var r vegeta.Result
+		if err = dec.Decode(&r); err != nil {
+			break
+		}
+
+		r.Attack = id
+		if err = enc.Encode(&r); err != nil {
+			_ = spool.Discard()
+			return nil, errors.Wrap(err, "failed to encode result")
+		}
+	}
+	if err != io.EOF {
+		_ = spool.Discard()
+		return nil, errors.Wrap(err, "failed to decode result")
+	}
+
+	if err = spool.Close(); err != nil {
+		_ = spool.Discard()
+		return nil, err
+	}
+	return spool, nil
+}
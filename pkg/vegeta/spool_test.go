@@ -0,0 +1,46 @@
+package vegeta
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSpool(t *testing.T) {
+	spool, err := NewSpool("test")
+	if err != nil {
+		t.Fatalf("NewSpool() error = %v", err)
+	}
+	defer spool.Discard() // nolint: errcheck
+
+	if _, err = spool.Write([]byte("result")); err != nil {
+		t.Fatalf("Spool.Write() error = %v", err)
+	}
+	if _, err = ioutil.ReadAll(spool); err == nil {
+		t.Error("Spool.Read() before Close succeeded")
+	}
+	if err = spool.Close(); err != nil {
+		t.Fatalf("Spool.Close() error = %v", err)
+	}
+
+	got, err := ioutil.ReadAll(spool)
+	if err != nil || string(got) != "result" {
+		t.Errorf("Spool.Read() = %s, %v, want result", got, err)
+	}
+
+	// The spool file can be read any number of times
+	got, err = ioutil.ReadAll(SpoolReader(spool.Path()))
+	if err != nil || string(got) != "result" {
+		t.Errorf("SpoolReader() = %s, %v, want result", got, err)
+	}
+
+	if err = spool.Discard(); err != nil {
+		t.Fatalf("Spool.Discard() error = %v", err)
+	}
+	if _, err = os.Stat(spool.Path()); !os.IsNotExist(err) {
+		t.Errorf("spool file %s not removed", spool.Path())
+	}
+	if _, err = ioutil.ReadAll(SpoolReader(spool.Path())); err == nil {
+		t.Error("SpoolReader() of a removed spool file succeeded")
+	}
+}
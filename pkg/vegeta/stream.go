@@ -0,0 +1,93 @@
+package vegeta
+
+import (
+	"time"
+	"vegeta-server/models"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+// StreamInterval is the interval at which the metrics of a running attack
+// are sent on the progress channel
+var StreamInterval = time.Second
+
+// LatencyBuckets are the upper bounds of the latency histogram in the metrics
+// of a running attack
+var LatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// NewLatencyHistogram returns a histogram with buckets matching LatencyBuckets
+func NewLatencyHistogram() *vegeta.Histogram {
+	return &vegeta.Histogram{
+		Buckets: append(vegeta.Buckets{0}, LatencyBuckets...),
+	}
+}
+
+// NewAttackMetrics creates a snapshot of the metrics and latency histogram
+// aggregated so far. The metrics are closed before the snapshot is taken,
+// which is safe to do repeatedly while results are still being added.
+func NewAttackMetrics(id string, m *vegeta.Metrics, h *vegeta.Histogram) models.AttackMetrics {
+	am := models.AttackMetrics{
+		ID:          id,
+		Buckets:     make([]models.LatencyBucket, 0, len(LatencyBuckets)),
+		StatusCodes: make(map[string]int),
+		Errors:      make([]string, 0),
+	}
+
+	// Bucket i of the histogram counts latencies in [Buckets[i], Buckets[i+1])
+	var count uint64
+	for i, le := range LatencyBuckets {
+		if i < len(h.Counts) {
+			count += h.Counts[i]
+		}
+		am.Buckets = append(am.Buckets, models.LatencyBucket{
+			UpperBound: le.Seconds(),
+			Count:      count,
+		})
+	}
+
+	// Closing metrics without any results yields NaN means and ratios
+	if m.Requests == 0 {
+		return am
+	}
+
+	m.Close()
+
+	am.Latencies.Total = int64(m.Latencies.Total)
+	am.Latencies.Mean = int64(m.Latencies.Mean)
+	am.Latencies.Max = int64(m.Latencies.Max)
+	am.Latencies.P50th = int64(m.Latencies.P50)
+	am.Latencies.P95th = int64(m.Latencies.P95)
+	am.Latencies.P99th = int64(m.Latencies.P99)
+	am.Requests = m.Requests
+	am.Rate = m.Rate
+	am.Success = m.Success
+
+	if !m.Earliest.IsZero() {
+		am.Earliest = m.Earliest.Format(time.RFC3339Nano)
+		am.Latest = m.Latest.Format(time.RFC3339Nano)
+	}
+
+	// Throughput is the rate of successful requests per second
+	if elapsed := m.Duration + m.Wait; elapsed > 0 {
+		am.Throughput = m.Success * float64(m.Requests) / elapsed.Seconds()
+	}
+
+	for code, count := range m.StatusCodes {
+		am.StatusCodes[code] = count
+	}
+	am.Errors = append(am.Errors, m.Errors...)
+
+	return am
+}
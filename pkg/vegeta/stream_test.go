@@ -0,0 +1,86 @@
+package vegeta
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"vegeta-server/models"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func TestNewAttackMetrics(t *testing.T) {
+	earliest := time.Date(2019, 3, 2, 22, 46, 47, 0, time.UTC)
+
+	// buckets returns the latency buckets, with count requests from index i
+	buckets := func(i int, count uint64) []models.LatencyBucket {
+		b := make([]models.LatencyBucket, 0)
+		for j, le := range LatencyBuckets {
+			var c uint64
+			if j >= i {
+				c = count
+			}
+			b = append(b, models.LatencyBucket{UpperBound: le.Seconds(), Count: c})
+		}
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		results []vegeta.Result
+		want    func() models.AttackMetrics
+	}{
+		{
+			name: "no results",
+			want: func() models.AttackMetrics {
+				return models.AttackMetrics{
+					ID:          "id",
+					Buckets:     buckets(0, 0),
+					StatusCodes: map[string]int{},
+					Errors:      []string{},
+				}
+			},
+		},
+		{
+			name: "results",
+			results: []vegeta.Result{
+				{Code: 200, Timestamp: earliest, Latency: time.Second},
+				{Code: 500, Timestamp: earliest.Add(time.Second), Latency: time.Second, Error: "500 Internal Server Error"},
+			},
+			want: func() models.AttackMetrics {
+				m := models.AttackMetrics{
+					ID:          "id",
+					Buckets:     buckets(8, 2),
+					Earliest:    "2019-03-02T22:46:47Z",
+					Latest:      "2019-03-02T22:46:48Z",
+					Requests:    2,
+					Rate:        2,
+					Throughput:  0.5,
+					Success:     0.5,
+					StatusCodes: map[string]int{"200": 1, "500": 1},
+					Errors:      []string{"500 Internal Server Error"},
+				}
+				m.Latencies.Total = int64(2 * time.Second)
+				m.Latencies.Mean = int64(time.Second)
+				m.Latencies.Max = int64(time.Second)
+				m.Latencies.P50th = int64(time.Second)
+				m.Latencies.P95th = int64(time.Second)
+				m.Latencies.P99th = int64(time.Second)
+				return m
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m vegeta.Metrics
+			h := NewLatencyHistogram()
+			for i := range tt.results {
+				m.Add(&tt.results[i])
+				h.Add(&tt.results[i])
+			}
+			if got, want := NewAttackMetrics("id", &m, h), tt.want(); !reflect.DeepEqual(got, want) {
+				t.Errorf("NewAttackMetrics() = %v, want %v", got, want)
+			}
+		})
+	}
+}
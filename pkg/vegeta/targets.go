@@ -0,0 +1,57 @@
+package vegeta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+// NewTargetsFromFile decodes all the targets in a vegeta target file using the
+// targeter matching the file format. The headers are merged with each target's
+// headers, and the body is used for targets that do not define their own.
+func NewTargetsFromFile(tf models.TargetFile, hdr http.Header, body []byte) ([]vegeta.Target, error) {
+	data, err := base64.StdEncoding.DecodeString(tf.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode target file data")
+	}
+
+	src := bytes.NewReader(data)
+
+	var tr vegeta.Targeter
+	switch tf.Format {
+	case vegeta.HTTPTargetFormat, "":
+		// Body file references would read files local to the server
+		if err := checkNoBodyFiles(data); err != nil {
+			return nil, err
+		}
+		tr = vegeta.NewHTTPTargeter(src, body, hdr)
+	case vegeta.JSONTargetFormat:
+		tr = vegeta.NewJSONTargeter(src, body, hdr)
+	default:
+		return nil, fmt.Errorf("target file format %s not supported", tf.Format)
+	}
+
+	tgts, err := vegeta.ReadAllTargets(tr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read targets from target file")
+	}
+
+	return tgts, nil
+}
+
+func checkNoBodyFiles(data []byte) error {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if strings.HasPrefix(strings.TrimSpace(sc.Text()), "@") {
+			return fmt.Errorf("body file references are not supported in target files")
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,139 @@
+package vegeta
+
+import (
+	"encoding/base64"
+	"net/http"
+	"reflect"
+	"testing"
+	"vegeta-server/models"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func TestNewTargetsFromFile(t *testing.T) {
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	type args struct {
+		tf   models.TargetFile
+		hdr  http.Header
+		body []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []vegeta.Target
+		wantErr bool
+	}{
+		{
+			name: "http format",
+			args: args{
+				tf: models.TargetFile{
+					Format: "http",
+					Data:   encode("GET http://localhost:80/a\nX-Target: a\n\nPOST http://localhost:80/b\n"),
+				},
+				hdr: http.Header{
+					"X-Attack": []string{"all"},
+				},
+				body: []byte("foo"),
+			},
+			want: []vegeta.Target{
+				{
+					Method: "GET",
+					URL:    "http://localhost:80/a",
+					Body:   []byte("foo"),
+					Header: http.Header{
+						"X-Attack": []string{"all"},
+						"X-Target": []string{"a"},
+					},
+				},
+				{
+					Method: "POST",
+					URL:    "http://localhost:80/b",
+					Body:   []byte("foo"),
+					Header: http.Header{
+						"X-Attack": []string{"all"},
+					},
+				},
+			},
+		},
+		{
+			name: "json format",
+			args: args{
+				tf: models.TargetFile{
+					Format: "json",
+					Data:   encode(`{"method": "POST", "url": "http://localhost:80/a", "body": "YmFy"}` + "\n"),
+				},
+			},
+			want: []vegeta.Target{
+				{
+					Method: "POST",
+					URL:    "http://localhost:80/a",
+					Body:   []byte("bar"),
+					Header: http.Header{},
+				},
+			},
+		},
+		{
+			name: "http format with body file",
+			args: args{
+				tf: models.TargetFile{
+					Format: "http",
+					Data:   encode("POST http://localhost:80/a\n@/etc/passwd\n"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported format",
+			args: args{
+				tf: models.TargetFile{
+					Format: "csv",
+					Data:   encode("GET,http://localhost:80/a\n"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad target",
+			args: args{
+				tf: models.TargetFile{
+					Format: "http",
+					Data:   encode("http://localhost:80/a\n"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty",
+			args: args{
+				tf: models.TargetFile{
+					Format: "json",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad base64",
+			args: args{
+				tf: models.TargetFile{
+					Format: "http",
+					Data:   "!",
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTargetsFromFile(tt.args.tf, tt.args.hdr, tt.args.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTargetsFromFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewTargetsFromFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
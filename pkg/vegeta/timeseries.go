@@ -0,0 +1,120 @@
+package vegeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+	"vegeta-server/models"
+
+	"github.com/pkg/errors"
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+// maxTimeseriesBuckets is the maximum number of buckets of a time series
+// report, which bounds the report size for short intervals
+const maxTimeseriesBuckets = 10000
+
+// ParseInterval parses the bucket interval of time series reports
+func ParseInterval(s string) (time.Duration, error) {
+	interval, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("failed to parse interval %s", s))
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("interval %s must be positive", s)
+	}
+	return interval, nil
+}
+
+// timeseries buckets the decoded results by interval, starting from the
+// earliest result, and returns the metrics of each bucket as JSON
+func timeseries(dec vegeta.Decoder, id string, format Format) ([]byte, error) {
+	interval, err := ParseInterval(format.Meta()["interval"])
+	if err != nil {
+		return nil, err
+	}
+
+	// Results are bucketed relative to the first decoded result, as merged
+	// results are only roughly sorted
+	var origin time.Time
+	buckets := make(map[int64]*vegeta.Metrics)
+	for {
+		var r vegeta.Result
+		if err = dec.Decode(&r); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "failed to decode result")
+		}
+
+		if origin.IsZero() {
+			origin = r.Timestamp
+		}
+		i := int64(r.Timestamp.Sub(origin) / interval)
+		if r.Timestamp.Before(origin) && r.Timestamp.Sub(origin)%interval != 0 {
+			i--
+		}
+
+		m, ok := buckets[i]
+		if !ok {
+			if len(buckets) == maxTimeseriesBuckets {
+				return nil, fmt.Errorf("interval %s yields more than %d buckets", interval, maxTimeseriesBuckets)
+			}
+			m = &vegeta.Metrics{}
+			buckets[i] = m
+		}
+		m.Add(&r)
+	}
+
+	indexes := make([]int64, 0, len(buckets))
+	for i := range buckets {
+		indexes = append(indexes, i)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	resp := models.TimeseriesReportResponse{
+		ID:       id,
+		Interval: interval.String(),
+		Buckets:  make([]models.TimeseriesBucket, 0, len(indexes)),
+	}
+	if len(indexes) > 0 {
+		first, last := indexes[0], indexes[len(indexes)-1]
+		if last-first >= maxTimeseriesBuckets {
+			return nil, fmt.Errorf("interval %s yields more than %d buckets", interval, maxTimeseriesBuckets)
+		}
+
+		// Intervals without results are listed as empty buckets
+		for i := first; i <= last; i++ {
+			resp.Buckets = append(resp.Buckets, timeseriesBucket(origin.Add(time.Duration(i)*interval), buckets[i]))
+		}
+	}
+
+	return json.Marshal(resp)
+}
+
+// timeseriesBucket returns the bucket starting at the time, with the metrics
+// of its results if any
+func timeseriesBucket(start time.Time, m *vegeta.Metrics) models.TimeseriesBucket {
+	b := models.TimeseriesBucket{
+		Start:       start.Format(time.RFC3339Nano),
+		StatusCodes: make(map[string]int),
+	}
+	if m == nil {
+		return b
+	}
+
+	m.Close()
+
+	b.Latencies.Mean = int64(m.Latencies.Mean)
+	b.Latencies.Max = int64(m.Latencies.Max)
+	b.Latencies.P50th = int64(m.Latencies.P50)
+	b.Latencies.P95th = int64(m.Latencies.P95)
+	b.Latencies.P99th = int64(m.Latencies.P99)
+	b.Requests = m.Requests
+	b.Success = m.Success
+	b.StatusCodes = m.StatusCodes
+
+	return b
+}
@@ -0,0 +1,86 @@
+package vegeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+	"vegeta-server/models"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func TestCreateReportFromReader_Timeseries(t *testing.T) {
+	t0 := time.Date(2019, 3, 2, 22, 46, 47, 0, time.UTC)
+	results := []vegeta.Result{
+		{Code: 200, Timestamp: t0, Latency: 10 * time.Millisecond},
+		{Code: 500, Timestamp: t0.Add(200 * time.Millisecond), Latency: 30 * time.Millisecond},
+		{Code: 200, Timestamp: t0.Add(2500 * time.Millisecond), Latency: 20 * time.Millisecond},
+		// Merged results are only roughly sorted
+		{Code: 200, Timestamp: t0.Add(-500 * time.Millisecond), Latency: 40 * time.Millisecond},
+	}
+
+	var buf bytes.Buffer
+	enc := vegeta.NewEncoder(&buf)
+	for i := range results {
+		if err := enc.Encode(&results[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	format := NewTimeseriesFormat()
+	format.SetMeta("interval", "1s")
+	b, err := CreateReportFromReader(&buf, "id", format)
+	if err != nil {
+		t.Fatalf("CreateReportFromReader() error = %v", err)
+	}
+
+	var got models.TimeseriesReportResponse
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "id" || got.Interval != "1s" || len(got.Buckets) != 4 {
+		t.Fatalf("CreateReportFromReader() = %+v, want 4 buckets", got)
+	}
+
+	tests := []struct {
+		start    time.Time
+		requests uint64
+		success  float64
+		max      time.Duration
+	}{
+		{t0.Add(-time.Second), 1, 1, 40 * time.Millisecond},
+		{t0, 2, 0.5, 30 * time.Millisecond},
+		{t0.Add(time.Second), 0, 0, 0},
+		{t0.Add(2 * time.Second), 1, 1, 20 * time.Millisecond},
+	}
+	for i, tt := range tests {
+		b := got.Buckets[i]
+		if b.Start != tt.start.Format(time.RFC3339Nano) || b.Requests != tt.requests || b.Success != tt.success || b.Latencies.Max != int64(tt.max) {
+			t.Errorf("bucket %d = %+v, want %+v", i, b, tt)
+		}
+	}
+	if got.Buckets[1].StatusCodes["500"] != 1 {
+		t.Errorf("bucket 1 status codes = %v", got.Buckets[1].StatusCodes)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		interval string
+		wantErr  bool
+	}{
+		{"1s", false},
+		{"250ms", false},
+		{"0s", true},
+		{"-1s", true},
+		{"second", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			if _, err := ParseInterval(tt.interval); (err != nil) != tt.wantErr {
+				t.Errorf("ParseInterval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package vegeta
 
 import (
-	"bytes"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io"
+	"time"
 	"vegeta-server/models"
 
 	"github.com/pkg/errors"
@@ -27,6 +27,7 @@ func tlsConfig(insecure bool, key, cert string, rootCerts []string) (*tls.Config
 		c.RootCAs = x509.NewCertPool()
 		for _, rootCert := range rootCerts {
 			if !c.RootCAs.AppendCertsFromPEM([]byte(rootCert)) {
+				err = fmt.Errorf("invalid root certificate")
 				log.WithError(err).Error("Vegeta TLS config failed")
 				return nil, errors.Wrap(err, "Vegeta TLS config failed")
 			}
@@ -35,13 +36,13 @@ func tlsConfig(insecure bool, key, cert string, rootCerts []string) (*tls.Config
 	return &c, nil
 }
 
-func attackWithOpts(opts *AttackOpts) (*vegeta.Attacker, <-chan *vegeta.Result) {
+func attackWithOpts(opts *AttackOpts) (*vegeta.Attacker, <-chan *vegeta.Result, error) {
 	var c *tls.Config
 
 	if opts.Cert != "" && opts.Key != "" {
 		tlsConfig, err := tlsConfig(opts.Insecure, opts.Key, opts.Cert, opts.RootCerts)
 		if err != nil {
-			return nil, nil
+			return nil, nil, categorize(models.AttackErrorCategoryTLSConfig, err)
 		}
 		c = tlsConfig
 	}
@@ -59,28 +60,46 @@ func attackWithOpts(opts *AttackOpts) (*vegeta.Attacker, <-chan *vegeta.Result)
 		vegeta.LocalAddr(*opts.Laddr.IPAddr),
 	)
 
-	tr := vegeta.NewStaticTargeter(opts.Target)
+	// Targets are hit in a round-robin fashion
+	tr := vegeta.NewStaticTargeter(opts.Targets...)
 
-	return atk, atk.Attack(tr, opts.Rate, opts.Duration, opts.Name)
+	return atk, atk.Attack(tr, opts.Pacer, opts.Duration, opts.Name), nil
 }
 
-// Attack implements the AttackFunc type for a vegeta based attacker
-func Attack(name string, params models.AttackParams, quit chan struct{}) (io.Reader, error) {
+// Attack implements the AttackFunc type for a vegeta based attacker.
+// If a progress channel is passed, a snapshot of the metrics aggregated so far
+// is sent on it every StreamInterval, and once more when the attack ends.
+// Results are written to a Spool in SpoolDir as they come, and the closed
+// spool is returned. The category of a returned error is given by ErrorCategory.
+func Attack(name string, params models.AttackParams, quit chan struct{}, progress chan<- models.AttackMetrics) (io.Reader, error) { // nolint: lll
 	opts, err := NewAttackOptsFromAttackParams(name, params)
 	if err != nil {
 		log.WithError(err).Error("vegeta attack failed")
-		return nil, errors.Wrap(err, "vegeta attack failed")
+		return nil, errors.Wrap(categorize(models.AttackErrorCategoryParamValidation, err), "vegeta attack failed")
 	}
 
-	atk, result := attackWithOpts(opts)
-	if result == nil {
-		err := fmt.Errorf("empty channel returned")
+	atk, result, err := attackWithOpts(opts)
+	if err != nil {
 		log.WithError(err).Error("vegeta attack failed")
 		return nil, errors.Wrap(err, "vegeta attack failed")
 	}
 
-	buf := bytes.NewBuffer(nil)
-	enc := vegeta.NewEncoder(buf)
+	var m vegeta.Metrics
+	h := NewLatencyHistogram()
+	var tick <-chan time.Time
+	if progress != nil {
+		ticker := time.NewTicker(StreamInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	spool, err := NewSpool(name)
+	if err != nil {
+		log.WithError(err).Error("vegeta attack failed")
+		return nil, errors.Wrap(categorize(models.AttackErrorCategoryInternal, err), "vegeta attack failed")
+	}
+
+	enc := vegeta.NewEncoder(spool)
 loop:
 	for {
 		select {
@@ -89,14 +108,55 @@ loop:
 				break loop
 			}
 			if err := enc.Encode(r); err != nil {
+				atk.Stop()
+				_ = spool.Discard()
 				log.WithError(err).Error("Vegeta attack failed")
-				return nil, errors.Wrap(err, "failed to encode result, vegeta attack failed")
+				return nil, errors.Wrap(
+					categorize(models.AttackErrorCategoryEncode, err),
+					"failed to encode result, vegeta attack failed",
+				)
+			}
+			if progress != nil {
+				m.Add(r)
+				h.Add(r)
+			}
+		case <-tick:
+			if !sendProgress(progress, NewAttackMetrics(name, &m, h), quit) {
+				atk.Stop()
+				_ = spool.Discard()
+				return nil, nil
 			}
 		case <-quit:
 			atk.Stop()
+			_ = spool.Discard()
 			return nil, nil
 		}
 	}
 
-	return buf, nil
+	if progress != nil && !sendProgress(progress, NewAttackMetrics(name, &m, h), quit) {
+		_ = spool.Discard()
+		return nil, nil
+	}
+
+	if err := spool.Close(); err != nil {
+		_ = spool.Discard()
+		log.WithError(err).Error("Vegeta attack failed")
+		return nil, errors.Wrap(
+			categorize(models.AttackErrorCategoryEncode, err),
+			"failed to write results, vegeta attack failed",
+		)
+	}
+
+	return spool, nil
+}
+
+// sendProgress sends the metrics on the progress channel, unless the attack is
+// canceled first. It returns false if the attack was canceled.
+func sendProgress(progress chan<- models.AttackMetrics, m models.AttackMetrics, quit chan struct{}) bool {
+	select {
+	case progress <- m:
+		return true
+	case <-quit:
+		return false
+	}
 }